@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ExitSkipped is the exit code used when a run is refused by the run guard
+const ExitSkipped = 3
+
+// errRunInProgress is returned when another live process holds the lock
+var errRunInProgress = errors.New("another run is in progress")
+
+// runLock is a PID lockfile preventing overlapping runs
+type runLock struct {
+	path string
+}
+
+// acquireRunLock creates the lockfile holding this process's PID. A lockfile
+// left behind by a process that no longer exists is replaced.
+func acquireRunLock(path string) (*runLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return &runLock{path: path}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if pid, ok := lockHolder(path); ok && processAlive(pid) {
+			return nil, fmt.Errorf("%w (pid %d holds %s)", errRunInProgress, pid, path)
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("%w (could not take %s)", errRunInProgress, path)
+}
+
+// Release removes the lockfile
+func (l *runLock) Release() {
+	os.Remove(l.path)
+}
+
+// lockHolder reads the PID stored in the lockfile
+func lockHolder(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil && pid > 0
+}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
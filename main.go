@@ -2,15 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"uptime-monitor/uptime"
 )
 
 func main() {
-	subject := "Failed trying to submit the report to API"
+	dryRunDefault, _ := strconv.ParseBool(os.Getenv("DRY_RUN"))
+	replay := flag.String("replay", "", "re-submit and re-notify a saved report instead of running checks")
+	dryRun := flag.Bool("dry-run", dryRunDefault, "validate the config and probe notification channels without running checks")
+	flag.Parse()
 
 	logger, err := setupMonitorLogger()
 	if err != nil {
@@ -19,43 +31,97 @@ func main() {
 	}
 	defer logger.Sync()
 
-	config, err := NewMonitorConfig()
+	config, err := uptime.NewMonitorConfig()
 	if err != nil {
 		logger.Fatal("Failed to load configuration", zap.Error(err))
 	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n  - %s\n",
+			strings.ReplaceAll(err.Error(), "\n", "\n  - "))
+		os.Exit(1)
+	}
+	// The library can run without an API, but the command always submits
+	if len(config.APITargets) == 0 {
+		logger.Fatal("Failed to load configuration", zap.Error(errors.New("API_URL or API_TARGETS environment variable not set")))
+	}
+
+	if len(config.DroppedDomains) > 0 {
+		logger.Warn("Ignoring empty or duplicate domain entries",
+			zap.Strings("dropped", config.DroppedDomains))
+	}
 
-	monitor := NewUptimeMonitor(config, logger)
+	monitor := uptime.NewUptimeMonitor(config, logger)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	if *dryRun {
+		os.Exit(monitor.DryRun(os.Stdout))
+	}
 
-	report, err := monitor.RunCheck(ctx)
-	if err != nil {
-		logger.Fatal("Monitoring failed", zap.Error(err))
+	if err := monitor.LoadHistory(); err != nil {
+		logger.Warn("Failed to load history cache", zap.Error(err))
 	}
 
-	if _, err := monitor.SaveReport(report); err != nil {
-		logger.Error("Failed to save report", zap.Error(err))
+	if *replay != "" {
+		if err := monitor.Replay(context.Background(), *replay); err != nil {
+			logger.Fatal("Replay failed", zap.Error(err))
+		}
+		return
 	}
 
-	if err := monitor.SubmitToAPI(ctx, report); err != nil {
-		logger.Error("Failed to submit report to API", zap.Error(err))
-		monitor.SendEmailOnFailure(report, &subject)
+	lock, err := acquireRunLock(config.LockFile)
+	if err != nil {
+		if errors.Is(err, errRunInProgress) {
+			logger.Warn("Skipping run", zap.Error(err))
+			os.Exit(ExitSkipped)
+		}
+		logger.Fatal("Failed to acquire run lock", zap.Error(err))
 	}
 
-	monitor.SendNotifications(ctx, report)
+	if last := monitor.LatestReport(); last != nil && config.MinRunInterval > 0 {
+		if since := time.Since(last.Timestamp); since < config.MinRunInterval {
+			lock.Release()
+			logger.Warn("Skipping run: previous run finished too recently",
+				zap.Duration("since_last_run", since),
+				zap.Duration("min_interval", config.MinRunInterval))
+			os.Exit(ExitSkipped)
+		}
+	}
 
-	exitCode := 0
-	if report.Downtime > 0 {
-		exitCode = 1
+	if config.Interval > 0 {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		monitor.RunDaemon(ctx)
+		stop()
+		lock.Release()
+		return
 	}
 
-	logger.Info("Monitoring completed successfully",
-		zap.Int("exit_code", exitCode),
-		zap.Float64("uptime_percent", report.UptimePercent),
-		zap.Int("total_checks", report.TotalChecks),
-		zap.Int("degraded", report.Degraded),
-	)
+	_, exitCode, err := monitor.RunCycle(context.Background())
+	if err := monitor.SaveHistory(); err != nil {
+		logger.Error("Failed to persist history cache", zap.Error(err))
+	}
+	lock.Release()
+	if err != nil {
+		logger.Fatal("Monitoring failed", zap.Error(err))
+	}
 
 	os.Exit(exitCode)
 }
+
+func setupMonitorLogger() (*zap.Logger, error) {
+	config := zap.NewProductionConfig()
+	config.EncoderConfig.TimeKey = "timestamp"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	logLevel := os.Getenv("LOG_LEVEL")
+	switch strings.ToLower(logLevel) {
+	case "debug":
+		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	case "warn":
+		config.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
+	case "error":
+		config.Level = zap.NewAtomicLevelAt(zap.ErrorLevel)
+	default:
+		config.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	}
+
+	return config.Build()
+}
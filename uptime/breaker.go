@@ -0,0 +1,126 @@
+package uptime
+
+import (
+	"sync"
+	"time"
+)
+
+const DefaultBreakerProbeInterval = 10 * time.Minute
+
+// breakerState tracks consecutive failed runs for a single domain
+type breakerState struct {
+	Failures  int       `json:"failures"`
+	OpenedAt  time.Time `json:"opened_at,omitempty"`
+	LastProbe time.Time `json:"last_probe,omitempty"`
+}
+
+// CircuitBreaker short-circuits checks for domains that have been down for
+// threshold consecutive runs, letting one probe through every probeInterval
+// to detect recovery. A threshold of 0 disables it.
+type CircuitBreaker struct {
+	mu            sync.Mutex
+	threshold     int
+	probeInterval time.Duration
+	states        map[string]*breakerState
+}
+
+// NewCircuitBreaker creates a breaker opening after threshold consecutive failures
+func NewCircuitBreaker(threshold int, probeInterval time.Duration) *CircuitBreaker {
+	if probeInterval <= 0 {
+		probeInterval = DefaultBreakerProbeInterval
+	}
+	return &CircuitBreaker{
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		states:        make(map[string]*breakerState),
+	}
+}
+
+// Allow reports whether domain should be checked. While the circuit is open it
+// returns true once per probe interval so the domain can prove it recovered.
+func (cb *CircuitBreaker) Allow(domain string) bool {
+	if cb.threshold <= 0 {
+		return true
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st, ok := cb.states[domain]
+	if !ok || st.Failures < cb.threshold {
+		return true
+	}
+
+	since := st.OpenedAt
+	if st.LastProbe.After(since) {
+		since = st.LastProbe
+	}
+	if time.Since(since) < cb.probeInterval {
+		return false
+	}
+
+	st.LastProbe = time.Now()
+	return true
+}
+
+// Record updates the domain's failure streak with the outcome of a check
+func (cb *CircuitBreaker) Record(domain string, down bool) {
+	if cb.threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !down {
+		delete(cb.states, domain)
+		return
+	}
+
+	st, ok := cb.states[domain]
+	if !ok {
+		st = &breakerState{}
+		cb.states[domain] = st
+	}
+
+	st.Failures++
+	if st.Failures == cb.threshold {
+		st.OpenedAt = time.Now()
+	}
+}
+
+// IsOpen reports whether the circuit for domain is currently open
+func (cb *CircuitBreaker) IsOpen(domain string) bool {
+	if cb.threshold <= 0 {
+		return false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	st, ok := cb.states[domain]
+	return ok && st.Failures >= cb.threshold
+}
+
+// snapshot copies the breaker state for persistence
+func (cb *CircuitBreaker) snapshot() map[string]breakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	out := make(map[string]breakerState, len(cb.states))
+	for domain, st := range cb.states {
+		out[domain] = *st
+	}
+	return out
+}
+
+// restore replaces the breaker state with a persisted snapshot
+func (cb *CircuitBreaker) restore(states map[string]breakerState) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.states = make(map[string]*breakerState, len(states))
+	for domain, st := range states {
+		cb.states[domain] = &st
+	}
+}
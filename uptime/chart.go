@@ -0,0 +1,368 @@
+package uptime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+const (
+	ChartFormatPNG = "png" // base64 PNG, uploaded or embedded as a data URI
+	ChartFormatSVG = "svg" // SVG markup, inlined into the HTML report
+)
+
+// ChartOptions sizes and colours the report charts
+type ChartOptions struct {
+	Format        string // png or svg
+	Width         int    // pixels, shared by every chart
+	Height        int    // pixels, for the uptime and trend charts
+	BarWidth      int    // uptime chart bar width in pixels
+	UpColor       drawing.Color
+	DownColor     drawing.Color
+	DegradedColor drawing.Color
+}
+
+// DefaultChartOptions returns the built-in chart size and colours
+func DefaultChartOptions() ChartOptions {
+	return ChartOptions{
+		Format:        ChartFormatPNG,
+		Width:         800,
+		Height:        400,
+		BarWidth:      80,
+		UpColor:       drawing.ColorGreen,
+		DownColor:     drawing.ColorRed,
+		DegradedColor: drawing.ColorYellow,
+	}
+}
+
+var hexColorPattern = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseChartOptions reads CHART_FORMAT, CHART_WIDTH, CHART_HEIGHT,
+// CHART_BAR_WIDTH and CHART_COLORS (e.g. "up=#2ecc71,down=#e74c3c,degraded=#f39c12")
+func parseChartOptions() (ChartOptions, error) {
+	opts := DefaultChartOptions()
+
+	opts.Format = strings.ToLower(getEnvOrDefault("CHART_FORMAT", ChartFormatPNG))
+	if opts.Format != ChartFormatPNG && opts.Format != ChartFormatSVG {
+		return opts, fmt.Errorf("CHART_FORMAT must be png or svg, got %q", opts.Format)
+	}
+
+	for name, dst := range map[string]*int{
+		"CHART_WIDTH":     &opts.Width,
+		"CHART_HEIGHT":    &opts.Height,
+		"CHART_BAR_WIDTH": &opts.BarWidth,
+	} {
+		raw := getEnv(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return opts, fmt.Errorf("%s %q is not a whole number", name, raw)
+		}
+		*dst = n
+	}
+
+	raw := getEnv("CHART_COLORS")
+	if raw == "" {
+		return opts, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		value = strings.TrimSpace(value)
+		if !ok || !hexColorPattern.MatchString(value) {
+			return opts, fmt.Errorf("invalid CHART_COLORS entry %q, want status=#rrggbb", pair)
+		}
+		color := drawing.ColorFromHex(strings.TrimPrefix(value, "#"))
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case StatusUp:
+			opts.UpColor = color
+		case StatusDown:
+			opts.DownColor = color
+		case StatusDegraded:
+			opts.DegradedColor = color
+		default:
+			return opts, fmt.Errorf("unknown CHART_COLORS status %q", key)
+		}
+	}
+	return opts, nil
+}
+
+// statusColor returns the configured colour for a status, grey for blocked
+func (o ChartOptions) statusColor(status string) drawing.Color {
+	switch status {
+	case StatusUp:
+		return o.UpColor
+	case StatusDegraded:
+		return o.DegradedColor
+	case StatusDown:
+		return o.DownColor
+	default:
+		return drawing.ColorFromHex("95a5a6")
+	}
+}
+
+// renderer returns the go-chart renderer for the configured format
+func (o ChartOptions) renderer() chart.RendererProvider {
+	if o.Format == ChartFormatSVG {
+		return chart.SVG
+	}
+	return chart.PNG
+}
+
+// encode turns rendered chart bytes into what the generators return: SVG
+// markup as is, PNG as base64
+func (o ChartOptions) encode(data []byte) string {
+	if o.Format == ChartFormatSVG {
+		return string(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// generateUptimeChart draws the run's up/down/degraded counts as a bar chart
+// in opts.Format
+func generateUptimeChart(report *MonitorReport, opts ChartOptions) (string, error) {
+	Colors := []drawing.Color{
+		opts.UpColor,
+		opts.DownColor,
+		opts.DegradedColor,
+	}
+
+	graph := chart.BarChart{
+		Title: "Uptime Overview Report",
+		TitleStyle: chart.Style{
+			FontSize:  16,
+			FontColor: drawing.ColorFromHex("2f2e41"),
+		},
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    40,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+			FillColor: drawing.ColorWhite,
+		},
+		Width:    opts.Width,
+		Height:   opts.Height,
+		BarWidth: opts.BarWidth,
+		Bars: []chart.Value{
+			{
+				Value: float64(report.Uptime),
+				Label: "Uptime",
+				Style: chart.Style{FillColor: Colors[0], FontSize: 8, FontColor: Colors[0]}},
+			{
+				Value: float64(report.Downtime),
+				Label: "Downtime",
+				Style: chart.Style{FillColor: Colors[1], FontSize: 8, FontColor: Colors[1]}},
+			{
+				Value: float64(report.Degraded),
+				Label: "Degraded",
+				Style: chart.Style{FillColor: Colors[2], FontSize: 8, FontColor: Colors[2]}},
+		},
+		XAxis: chart.Style{
+			FontSize: 10,
+		},
+		YAxis: chart.YAxis{
+			Name: "Powered By Axiolot Hub",
+			Style: chart.Style{
+				FontSize: 8,
+			},
+		},
+		Canvas: chart.Style{
+			FillColor: drawing.ColorFromHex("f8f9fa"),
+		},
+	}
+
+	var buf bytes.Buffer
+	err := graph.Render(opts.renderer(), &buf)
+	if err != nil {
+		return "", err
+	}
+
+	return opts.encode(buf.Bytes()), nil
+}
+
+// minTrendPoints is the fewest runs a trend chart is drawn for
+const minTrendPoints = 2
+
+// generateTrendChart plots uptime percent and average latency across runs,
+// oldest first, in opts.Format. Latency uses the right-hand axis.
+func generateTrendChart(history []*MonitorReport, opts ChartOptions) (string, error) {
+	if len(history) < minTrendPoints {
+		return "", fmt.Errorf("trend chart needs at least %d runs, got %d", minTrendPoints, len(history))
+	}
+
+	times := make([]time.Time, len(history))
+	uptime := make([]float64, len(history))
+	latency := make([]float64, len(history))
+	for i, report := range history {
+		times[i] = report.Timestamp
+		uptime[i] = report.UptimePercent
+		latency[i] = report.AverageLatency
+	}
+
+	graph := chart.Chart{
+		Title: "Uptime & Latency Trend",
+		TitleStyle: chart.Style{
+			FontSize:  16,
+			FontColor: drawing.ColorFromHex("2f2e41"),
+		},
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    40,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+			FillColor: drawing.ColorWhite,
+		},
+		Width:  opts.Width,
+		Height: opts.Height,
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeValueFormatterWithFormat("01-02 15:04"),
+			Style:          chart.Style{FontSize: 8},
+		},
+		YAxis: chart.YAxis{
+			Name:  "Uptime %",
+			Range: &chart.ContinuousRange{Min: 0, Max: 100},
+			Style: chart.Style{FontSize: 8},
+		},
+		YAxisSecondary: chart.YAxis{
+			Name:  "Avg Latency (ms)",
+			Style: chart.Style{FontSize: 8},
+		},
+		Canvas: chart.Style{
+			FillColor: drawing.ColorFromHex("f8f9fa"),
+		},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				Name:    "Uptime %",
+				XValues: times,
+				YValues: uptime,
+				Style:   chart.Style{StrokeColor: opts.UpColor, StrokeWidth: 2},
+			},
+			chart.TimeSeries{
+				Name:    "Avg Latency (ms)",
+				YAxis:   chart.YAxisSecondary,
+				XValues: times,
+				YValues: latency,
+				Style:   chart.Style{StrokeColor: drawing.ColorBlue, StrokeWidth: 2},
+			},
+		},
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	var buf bytes.Buffer
+	if err := graph.Render(opts.renderer(), &buf); err != nil {
+		return "", err
+	}
+
+	return opts.encode(buf.Bytes()), nil
+}
+
+// LatencyChartMaxBars caps the domains plotted by generateLatencyChart; the
+// slowest are kept
+const LatencyChartMaxBars = 15
+
+// slowestResults returns up to limit results ordered slowest first
+func slowestResults(results []HealthCheckResult, limit int) []HealthCheckResult {
+	sorted := make([]HealthCheckResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ResponseTime > sorted[j].ResponseTime
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+// generateLatencyChart draws each domain's response time as a horizontal bar
+// coloured by status, slowest first and capped to LatencyChartMaxBars, in
+// opts.Format. go-chart's BarChart is vertical only, so the bars are drawn
+// directly on a renderer.
+func generateLatencyChart(report *MonitorReport, opts ChartOptions) (string, error) {
+	results := slowestResults(report.Results, LatencyChartMaxBars)
+	if len(results) == 0 {
+		return "", fmt.Errorf("no results to chart")
+	}
+
+	width := opts.Width
+	labelWidth := min(240, width/3) // narrow charts give the labels less room
+	const (
+		top        = 50
+		rowHeight  = 26
+		barHeight  = 16
+		valueWidth = 80
+		padding    = 20
+	)
+	height := top + len(results)*rowHeight + padding
+
+	font, err := chart.GetDefaultFont()
+	if err != nil {
+		return "", err
+	}
+	r, err := opts.renderer()(width, height)
+	if err != nil {
+		return "", err
+	}
+
+	chart.Draw.Box(r, chart.Box{Top: 0, Left: 0, Right: width, Bottom: height}, chart.Style{FillColor: drawing.ColorWhite, StrokeColor: drawing.ColorWhite})
+
+	textStyle := func(size float64, color drawing.Color) {
+		chart.Style{Font: font, FontSize: size, FontColor: color}.WriteTextOptionsToRenderer(r)
+	}
+	textStyle(16, drawing.ColorFromHex("2f2e41"))
+	r.Text("Response Time by Domain", padding, 30)
+
+	var slowest int64 = 1
+	for _, result := range results {
+		if result.ResponseTime > slowest {
+			slowest = result.ResponseTime
+		}
+	}
+	barLeft := padding + labelWidth
+	barSpan := max(width-barLeft-valueWidth-padding, 1)
+
+	for i, result := range results {
+		y := top + i*rowHeight
+		barWidth := int(float64(barSpan) * float64(result.ResponseTime) / float64(slowest))
+		if barWidth < 1 {
+			barWidth = 1
+		}
+
+		chart.Draw.Box(r, chart.Box{Top: y, Left: barLeft, Right: barLeft + barWidth, Bottom: y + barHeight},
+			chart.Style{FillColor: opts.statusColor(result.Status), StrokeColor: opts.statusColor(result.Status)})
+
+		textStyle(9, drawing.ColorFromHex("333333"))
+		r.Text(chartLabel(result.Domain, 34), padding, y+barHeight-4)
+		r.Text(fmt.Sprintf("%d ms", result.ResponseTime), barLeft+barWidth+6, y+barHeight-4)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		return "", err
+	}
+
+	return opts.encode(buf.Bytes()), nil
+}
+
+// chartLabel shortens label to at most max runes, ending it with "..." when
+// cut, so IDN domains aren't split mid-character
+func chartLabel(label string, max int) string {
+	runes := []rune(label)
+	if len(runes) <= max {
+		return label
+	}
+	return string(runes[:max-3]) + "..."
+}
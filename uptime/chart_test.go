@@ -0,0 +1,27 @@
+package uptime
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestChartLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"example.com", "example.com"},
+		{"a-very-long-subdomain.example-domain.com", "a-very-long-subdomain.example-d..."},
+		{"日本語のとても長いドメイン名の例です日本語のとても長いドメイン名.jp", "日本語のとても長いドメイン名の例です日本語のとても長いドメイン..."},
+	}
+
+	for _, tt := range tests {
+		got := chartLabel(tt.label, 34)
+		if got != tt.want {
+			t.Errorf("chartLabel(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("chartLabel(%q) = %q, not valid UTF-8", tt.label, got)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package uptime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// ConfigFile is the schema of the optional CONFIG_FILE. Domains replace
+// MONITOR_DOMAINS and MONITOR_DOMAIN_CONFIG; Settings supply any other
+// environment variable by name. Variables already set in the environment
+// take precedence, so secrets can stay out of the file. Settings are never
+// exported into the process environment; see getEnv.
+type ConfigFile struct {
+	Domains  []ConfigFileDomain         `json:"domains"`
+	Settings map[string]json.RawMessage `json:"settings,omitempty"` // e.g. "MONITOR_TIMEOUT": "30s"
+}
+
+// ConfigFileDomain is a domain to check together with its overrides, which
+// use the same fields as a MONITOR_DOMAIN_CONFIG entry
+type ConfigFileDomain struct {
+	URL string `json:"url"`
+	DomainConfig
+}
+
+var settingNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// fileSettings holds the settings of the CONFIG_FILE last loaded by
+// NewMonitorConfig. lookupEnv falls back to them for variables missing from
+// the environment, so hooks and validators never inherit them.
+var fileSettings atomic.Pointer[map[string]string]
+
+// useFileSettings makes file's settings visible to lookupEnv, replacing any
+// from an earlier load. A nil file clears them.
+func useFileSettings(file *ConfigFile) {
+	if file == nil {
+		fileSettings.Store(nil)
+		return
+	}
+	settings := make(map[string]string, len(file.Settings))
+	for name, raw := range file.Settings {
+		settings[name] = settingValue(raw)
+	}
+	fileSettings.Store(&settings)
+}
+
+// lookupEnv is os.LookupEnv with the CONFIG_FILE settings as a fallback
+func lookupEnv(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	if settings := fileSettings.Load(); settings != nil {
+		value, ok := (*settings)[key]
+		return value, ok
+	}
+	return "", false
+}
+
+// getEnv is os.Getenv with the CONFIG_FILE settings as a fallback
+func getEnv(key string) string {
+	value, _ := lookupEnv(key)
+	return value
+}
+
+// loadConfigFile reads and validates the config file at path. It returns nil
+// when path is empty.
+func loadConfigFile(path string) (*ConfigFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+	}
+
+	var file ConfigFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid CONFIG_FILE %s: %w", path, err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("invalid CONFIG_FILE %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// loadDomainsFile reads MONITOR_DOMAINS_FILE: one domain per line, with
+// blank lines and # comments ignored. Every entry must pass validateDomain.
+func loadDomainsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MONITOR_DOMAINS_FILE: %w", err)
+	}
+
+	var domains []string
+	for i, line := range strings.Split(string(data), "\n") {
+		// A # starts a comment at the start of a line or after whitespace,
+		// so URLs with a fragment are left intact
+		if at := strings.Index(line, "#"); at == 0 || (at > 0 && (line[at-1] == ' ' || line[at-1] == '\t')) {
+			line = line[:at]
+		}
+		domain := strings.TrimSpace(line)
+		if domain == "" {
+			continue
+		}
+		if err := validateDomain(domain); err != nil {
+			return nil, fmt.Errorf("invalid MONITOR_DOMAINS_FILE %s: line %d: %q: %w", path, i+1, domain, err)
+		}
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// validate checks the domain entries and setting names
+func (f *ConfigFile) validate() error {
+	seen := make(map[string]bool, len(f.Domains))
+	for i, domain := range f.Domains {
+		domainURL := strings.TrimSpace(domain.URL)
+		if domainURL == "" {
+			return fmt.Errorf("domain %d has no url", i+1)
+		}
+		if seen[domainURL] {
+			return fmt.Errorf("domain %s is listed more than once", domainURL)
+		}
+		seen[domainURL] = true
+
+		if err := domain.DomainConfig.validate(); err != nil {
+			return fmt.Errorf("domain %s: %w", domain.URL, err)
+		}
+	}
+
+	for name := range f.Settings {
+		if !settingNamePattern.MatchString(name) {
+			return fmt.Errorf("setting %q is not an environment variable name", name)
+		}
+		switch name {
+		case "CONFIG_FILE", "MONITOR_DOMAINS", "MONITOR_DOMAIN_CONFIG":
+			return fmt.Errorf("setting %s is not allowed; list domains under \"domains\" instead", name)
+		}
+	}
+	return nil
+}
+
+// DomainList returns the configured domain URLs in file order
+func (f *ConfigFile) DomainList() []string {
+	domains := make([]string, 0, len(f.Domains))
+	for _, domain := range f.Domains {
+		domains = append(domains, strings.TrimSpace(domain.URL))
+	}
+	return domains
+}
+
+// settingValue converts a setting to its environment form: strings are used
+// as is, numbers and booleans as written, and objects or arrays (for settings
+// such as API_TARGETS) as compact JSON
+func settingValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
@@ -0,0 +1,81 @@
+package uptime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFileSettingsStayOutOfEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "domains": [{"url": "  https://example.com  ", "timeout_ms": 1500}],
+  "settings": {"MONITOR_TIMEOUT": "42s", "SAMPLES_PER_CHECK": 3}
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("SAMPLES_PER_CHECK", "2") // the environment wins
+	// t.Setenv restores these afterwards; they must be unset, not empty
+	for _, name := range []string{"MONITOR_DOMAINS", "MONITOR_TIMEOUT"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Cleanup(func() { useFileSettings(nil) })
+
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Timeout != 42*time.Second {
+		t.Errorf("Timeout = %s, want 42s from the file", config.Timeout)
+	}
+	if config.SamplesPerCheck != 2 {
+		t.Errorf("SamplesPerCheck = %d, want 2 from the environment", config.SamplesPerCheck)
+	}
+	if _, set := os.LookupEnv("MONITOR_TIMEOUT"); set {
+		t.Error("MONITOR_TIMEOUT was exported into the process environment")
+	}
+
+	m := &UptimeMonitor{config: config}
+	if got := m.domainSettings("https://example.com").Timeout; got != 1500 {
+		t.Errorf("padded URL lost its settings: timeout_ms = %d, want 1500", got)
+	}
+}
+
+func TestValidateReportsEveryBadSetting(t *testing.T) {
+	t.Setenv("MONITOR_DOMAINS", "example.com")
+	bad := map[string]string{
+		"DEFAULT_SCHEME":         "ftp",
+		"OUTPUT_COMPRESSION":     "zip",
+		"NOTIFY_COOLDOWN":        "soon",
+		"MIN_TLS_VERSION":        "1.4",
+		"SMTP_TLS_MODE":          "tls",
+		"HTML_MAX_ROWS":          "many",
+		"BREAKER_THRESHOLD":      "3x",
+		"BREAKER_PROBE_INTERVAL": "often",
+		"MIN_RUN_INTERVAL":       "1 minute",
+		"CHART_STORAGE":          "ftp",
+	}
+	for key, value := range bad {
+		t.Setenv(key, value)
+	}
+
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatalf("NewMonitorConfig() = %v, want problems left to Validate", err)
+	}
+	err = config.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want every bad setting reported")
+	}
+	for key := range bad {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Validate() does not mention %s:\n%v", key, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package uptime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// notifyState records when each domain was last alerted on, so NOTIFY_COOLDOWN
+// can hold back repeat alerts across runs
+type notifyState struct {
+	mu           sync.Mutex
+	path         string
+	LastNotified map[string]time.Time `json:"last_notified"`
+}
+
+// loadNotifyState reads the state file. A missing file yields an empty state.
+func loadNotifyState(path string) (*notifyState, error) {
+	state := &notifyState{path: path, LastNotified: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read notification state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse notification state: %w", err)
+	}
+	if state.LastNotified == nil {
+		state.LastNotified = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+// coolingDown reports whether domain was alerted on less than cooldown before now
+func (s *notifyState) coolingDown(domain string, now time.Time, cooldown time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, ok := s.LastNotified[domain]
+	return ok && now.Sub(last) < cooldown
+}
+
+// mark records that domains were alerted on at now
+func (s *notifyState) mark(domains []string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, domain := range domains {
+		s.LastNotified[domain] = now
+	}
+}
+
+// Save writes the state file atomically
+func (s *notifyState) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode notification state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create notification state directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write notification state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace notification state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package uptime
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DomainDiff describes how a domain moved between the previous run and this
+// one. Previous fields are empty for a domain that is new in this run;
+// current fields are empty for one that was dropped.
+type DomainDiff struct {
+	Domain          string `json:"domain"`
+	PreviousStatus  string `json:"previous_status,omitempty"`
+	Status          string `json:"status,omitempty"`
+	PreviousLatency int64  `json:"previous_latency_ms,omitempty"`
+	Latency         int64  `json:"latency_ms,omitempty"`
+	LatencyDelta    *int64 `json:"latency_delta_ms,omitempty"` // nil unless the domain was checked in both runs
+}
+
+// StatusChanged reports whether the domain's status differs from the previous run
+func (d DomainDiff) StatusChanged() bool {
+	return d.PreviousStatus != d.Status
+}
+
+// diffReports compares every domain in current against previous, followed by
+// any domains previous checked that current did not
+func diffReports(previous, current *MonitorReport) []DomainDiff {
+	diffs := make([]DomainDiff, 0, len(current.Results))
+	seen := make(map[string]bool, len(current.Results))
+
+	for _, result := range current.Results {
+		seen[result.Domain] = true
+		diff := DomainDiff{
+			Domain:  result.Domain,
+			Status:  result.Status,
+			Latency: result.ResponseTime,
+		}
+		if prev, ok := findResult(previous, result.Domain); ok {
+			delta := result.ResponseTime - prev.ResponseTime
+			diff.PreviousStatus = prev.Status
+			diff.PreviousLatency = prev.ResponseTime
+			diff.LatencyDelta = &delta
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, prev := range previous.Results {
+		if seen[prev.Domain] {
+			continue
+		}
+		diffs = append(diffs, DomainDiff{
+			Domain:          prev.Domain,
+			PreviousStatus:  prev.Status,
+			PreviousLatency: prev.ResponseTime,
+		})
+	}
+	return diffs
+}
+
+// latestSavedReport loads the newest JSON report in OutputDir, or returns
+// nil when there is none
+func (m *UptimeMonitor) latestSavedReport() (*MonitorReport, error) {
+	paths, err := m.savedReports()
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+	return LoadReport(paths[len(paths)-1])
+}
+
+// AttachChanges fills in report.Changes against the previous run: the last
+// report in history, or the newest one saved in OutputDir when history is
+// empty. On the first run report.Changes stays empty.
+func (m *UptimeMonitor) AttachChanges(report *MonitorReport) {
+	previous := m.history.Latest()
+	if previous == nil {
+		var err error
+		previous, err = m.latestSavedReport()
+		if err != nil {
+			m.logger.Warn("Failed to load previous report, skipping changes", zap.Error(err))
+			return
+		}
+	}
+	if previous == nil {
+		m.logger.Info("No previous report, skipping changes")
+		return
+	}
+
+	since := previous.Timestamp
+	report.ChangesSince = &since
+	report.Changes = diffReports(previous, report)
+}
+
+// maxLatencyMovers caps the latency rows in the HTML changes section
+const maxLatencyMovers = 5
+
+// buildChangesSection renders the status transitions and largest latency
+// moves since the previous run, or nothing when there was no previous run
+func buildChangesSection(report *MonitorReport) string {
+	if report.ChangesSince == nil {
+		return ""
+	}
+
+	var transitions, movers []DomainDiff
+	for _, d := range report.Changes {
+		if d.StatusChanged() {
+			transitions = append(transitions, d)
+		} else if d.LatencyDelta != nil && *d.LatencyDelta != 0 {
+			movers = append(movers, d)
+		}
+	}
+	sort.SliceStable(movers, func(i, j int) bool {
+		return abs64(*movers[i].LatencyDelta) > abs64(*movers[j].LatencyDelta)
+	})
+	if len(movers) > maxLatencyMovers {
+		movers = movers[:maxLatencyMovers]
+	}
+
+	rows := ""
+	for _, d := range append(transitions, movers...) {
+		delta := "-"
+		if d.LatencyDelta != nil {
+			delta = fmt.Sprintf("%+d ms", *d.LatencyDelta)
+		}
+		rows += fmt.Sprintf(`
+<tr>
+	<td>%s</td>
+	<td>%s &rarr; %s</td>
+	<td>%s</td>
+</tr>`, html.EscapeString(d.Domain), diffStatusLabel(d.PreviousStatus, "new"), diffStatusLabel(d.Status, "removed"), delta)
+	}
+	if rows == "" {
+		rows = `
+<tr><td colspan="3">No status or latency changes.</td></tr>`
+	}
+
+	return fmt.Sprintf(`
+    <div class="section">
+      <h2>Changes Since Last Run</h2>
+      <p>Compared with the run at %s.</p>
+      <div class="table-container">
+        <table>
+          <tr><th>Domain</th><th>Status</th><th>Latency Change</th></tr>
+          %s
+        </table>
+      </div>
+    </div>
+`, report.ChangesSince.Format(time.RFC1123), rows)
+}
+
+// diffStatusLabel renders a status for the changes table, using missing when
+// the domain was absent from that run
+func diffStatusLabel(status, missing string) string {
+	if status == "" {
+		return "<em>" + missing + "</em>"
+	}
+	return fmt.Sprintf(`<span class="status-%s">%s</span>`, status, strings.ToUpper(status))
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,27 @@
+package uptime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildChangesSectionEscapesDomain(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	report := &MonitorReport{
+		ChangesSince: &since,
+		Changes: []DomainDiff{{
+			Domain:         `example.com/<script>alert("x")</script>`,
+			PreviousStatus: StatusUp,
+			Status:         StatusDown,
+		}},
+	}
+
+	section := buildChangesSection(report)
+	if strings.Contains(section, "<script>") {
+		t.Fatalf("domain not escaped:\n%s", section)
+	}
+	if !strings.Contains(section, "&lt;script&gt;") {
+		t.Fatalf("escaped domain missing:\n%s", section)
+	}
+}
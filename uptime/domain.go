@@ -0,0 +1,281 @@
+package uptime
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DomainConfig holds per-domain overrides. Zero values fall back to the global
+// defaults so a domain without an entry behaves exactly as before.
+type DomainConfig struct {
+	FastThreshold      int64                   `json:"fast_threshold_ms,omitempty"`     // responses at or below this count as fast
+	DegradedThreshold  int64                   `json:"degraded_threshold_ms,omitempty"` // 2xx slower than this is degraded
+	DownThreshold      int64                   `json:"down_threshold_ms,omitempty"`     // any response slower than this is down
+	DependsOn          []string                `json:"depends_on,omitempty"`            // checks skipped when any of these is down
+	Steps              []TransactionStep       `json:"steps,omitempty"`                 // multi-step synthetic transaction
+	HostHeader         string                  `json:"host_header,omitempty"`           // Host header sent instead of the URL host
+	Headers            map[string]HeaderValues `json:"headers,omitempty"`               // extra request headers for the check
+	Auth               *DomainAuth             `json:"auth,omitempty"`                  // credentials read from environment variables
+	ServerName         string                  `json:"server_name,omitempty"`           // TLS SNI and certificate name to verify
+	InsecureSkipVerify bool                    `json:"insecure_skip_verify,omitempty"`  // skip certificate verification (self-signed internal hosts)
+	Priority           int                     `json:"priority,omitempty"`              // higher runs first within a dependency level
+	DegradedOnRetry    bool                    `json:"degraded_on_retry,omitempty"`     // a success that needed retries is degraded
+	Validator          string                  `json:"validator,omitempty"`             // command judging the response body
+	WarmUp             bool                    `json:"warm_up,omitempty"`               // send an untimed request before the timed check
+	Method             string                  `json:"method,omitempty"`                // HTTP method for the check, GET by default
+	ExpectStatus       []int                   `json:"expect_status,omitempty"`         // only these codes count as up
+	BodyMatch          string                  `json:"body_match,omitempty"`            // substring the response body must contain
+	BodyMatchStatus    string                  `json:"body_match_status,omitempty"`     // status when body_match is missing, down by default
+	BodyLimit          int64                   `json:"body_limit_bytes,omitempty"`      // how much of the body is read for body_match and validator
+	Timeout            int64                   `json:"timeout_ms,omitempty"`            // request timeout, the global MONITOR_TIMEOUT by default
+	Maintenance        []MaintenanceWindow     `json:"maintenance,omitempty"`           // planned work windows for this domain
+}
+
+// DomainAuth names the environment variables holding a domain's credentials,
+// so secrets never appear in MONITOR_DOMAIN_CONFIG, results or logs
+type DomainAuth struct {
+	Type        string `json:"type"`                   // basic or bearer
+	UsernameEnv string `json:"username_env,omitempty"` // basic
+	PasswordEnv string `json:"password_env,omitempty"` // basic
+	TokenEnv    string `json:"token_env,omitempty"`    // bearer
+}
+
+// apply sets the Authorization header on req from the configured variables
+func (a *DomainAuth) apply(req *http.Request) {
+	switch a.Type {
+	case "basic":
+		req.SetBasicAuth(getEnv(a.UsernameEnv), getEnv(a.PasswordEnv))
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+getEnv(a.TokenEnv))
+	}
+}
+
+// validate checks the auth type and that its variables are set
+func (a *DomainAuth) validate() error {
+	var vars []string
+	switch a.Type {
+	case "basic":
+		vars = []string{a.UsernameEnv, a.PasswordEnv}
+	case "bearer":
+		vars = []string{a.TokenEnv}
+	default:
+		return fmt.Errorf("auth type must be basic or bearer, got %q", a.Type)
+	}
+
+	for _, name := range vars {
+		if name == "" {
+			return fmt.Errorf("%s auth is missing an environment variable name", a.Type)
+		}
+		if getEnv(name) == "" {
+			return fmt.Errorf("auth environment variable %s is not set", name)
+		}
+	}
+	return nil
+}
+
+// HeaderValues holds one or more values for a header. In JSON it may be
+// written as a single string or an array of strings.
+type HeaderValues []string
+
+// UnmarshalJSON accepts either a string or an array of strings
+func (h *HeaderValues) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*h = HeaderValues{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("header values must be a string or an array of strings")
+	}
+	*h = multiple
+	return nil
+}
+
+// FastUnder returns the latency in ms at or below which a response is fast
+func (dc DomainConfig) FastUnder() int64 {
+	if dc.FastThreshold > 0 {
+		return dc.FastThreshold
+	}
+	return ThresholdFast
+}
+
+// DegradedAfter returns the latency in ms at which a successful response is degraded
+func (dc DomainConfig) DegradedAfter() int64 {
+	if dc.DegradedThreshold > 0 {
+		return dc.DegradedThreshold
+	}
+	return ThresholdAccept
+}
+
+// CheckMethod returns the HTTP method used for the domain's check
+func (dc DomainConfig) CheckMethod() string {
+	if dc.Method != "" {
+		return strings.ToUpper(dc.Method)
+	}
+	return http.MethodGet
+}
+
+// BodyReadLimit returns how many bytes of the response body are read for assertions
+func (dc DomainConfig) BodyReadLimit() int64 {
+	if dc.BodyLimit > 0 {
+		return dc.BodyLimit
+	}
+	return MaxStepBodySize
+}
+
+// BodyMismatchStatus returns the status of a check whose body lacks body_match
+func (dc DomainConfig) BodyMismatchStatus() string {
+	if dc.BodyMatchStatus != "" {
+		return dc.BodyMatchStatus
+	}
+	return StatusDown
+}
+
+// DownAfter returns the latency in ms at which a response is considered down,
+// or 0 when no hard limit applies
+func (dc DomainConfig) DownAfter() int64 {
+	return dc.DownThreshold
+}
+
+// parseDomainConfigs decodes MONITOR_DOMAIN_CONFIG, a JSON object keyed by domain
+func parseDomainConfigs(raw string) (map[string]DomainConfig, error) {
+	configs := make(map[string]DomainConfig)
+	if raw == "" {
+		return configs, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+		return nil, fmt.Errorf("invalid MONITOR_DOMAIN_CONFIG: %w", err)
+	}
+
+	for domain, dc := range configs {
+		if err := dc.validate(); err != nil {
+			return nil, fmt.Errorf("invalid MONITOR_DOMAIN_CONFIG for %s: %w", domain, err)
+		}
+	}
+
+	return configs, nil
+}
+
+// RequestTimeout returns the per-request timeout, or global when unset
+func (dc DomainConfig) RequestTimeout(global time.Duration) time.Duration {
+	if dc.Timeout > 0 {
+		return time.Duration(dc.Timeout) * time.Millisecond
+	}
+	return global
+}
+
+// validate checks the overrides are consistent
+func (dc DomainConfig) validate() error {
+	if dc.FastThreshold < 0 || dc.DegradedThreshold < 0 || dc.DownThreshold < 0 {
+		return fmt.Errorf("thresholds must not be negative")
+	}
+	if dc.FastThreshold > 0 && dc.FastThreshold >= dc.DegradedAfter() {
+		return fmt.Errorf("fast_threshold_ms (%d ms) must be below the degraded threshold (%d ms)", dc.FastUnder(), dc.DegradedAfter())
+	}
+	if dc.DownThreshold > 0 && dc.DownThreshold <= dc.DegradedAfter() {
+		return fmt.Errorf("down_threshold_ms must exceed the degraded threshold (%d ms)", dc.DegradedAfter())
+	}
+	switch dc.CheckMethod() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return fmt.Errorf("method must be GET, HEAD or OPTIONS, got %q", dc.Method)
+	}
+	switch dc.BodyMismatchStatus() {
+	case StatusDown, StatusDegraded:
+	default:
+		return fmt.Errorf("body_match_status must be down or degraded, got %q", dc.BodyMatchStatus)
+	}
+	if dc.BodyLimit < 0 {
+		return fmt.Errorf("body_limit_bytes must not be negative")
+	}
+	if dc.Timeout < 0 {
+		return fmt.Errorf("timeout_ms must not be negative")
+	}
+	if dc.Auth != nil {
+		if err := dc.Auth.validate(); err != nil {
+			return err
+		}
+	}
+	for i, step := range dc.Steps {
+		if step.URL == "" {
+			return fmt.Errorf("step %d has no url", i+1)
+		}
+	}
+	for i, w := range dc.Maintenance {
+		if len(w.Domains) > 0 {
+			return fmt.Errorf("maintenance window %d: domains only apply to MAINTENANCE_WINDOWS", i+1)
+		}
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("maintenance window %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// DomainSettings returns the overrides configured for domain, or the defaults
+func (c *MonitorConfig) DomainSettings(domain string) DomainConfig {
+	return c.DomainConfigs[domain]
+}
+
+// dependencyLevels orders domain indexes into levels where every domain only
+// depends on domains from earlier levels. It fails on unknown dependencies and
+// cycles so a bad graph is rejected before any check runs.
+func dependencyLevels(domains []string, configs map[string]DomainConfig) ([][]int, error) {
+	index := make(map[string]int, len(domains))
+	for i, domain := range domains {
+		index[domain] = i
+	}
+
+	pending := make([]int, len(domains))
+	dependents := make([][]int, len(domains))
+	for i, domain := range domains {
+		for _, parent := range configs[domain].DependsOn {
+			p, ok := index[parent]
+			if !ok {
+				return nil, fmt.Errorf("%s depends on unknown domain %s", domain, parent)
+			}
+			if p == i {
+				return nil, fmt.Errorf("%s depends on itself", domain)
+			}
+			pending[i]++
+			dependents[p] = append(dependents[p], i)
+		}
+	}
+
+	var levels [][]int
+	var current []int
+	for i := range domains {
+		if pending[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	resolved := 0
+	for len(current) > 0 {
+		levels = append(levels, current)
+		resolved += len(current)
+
+		var next []int
+		for _, i := range current {
+			for _, child := range dependents[i] {
+				pending[child]--
+				if pending[child] == 0 {
+					next = append(next, child)
+				}
+			}
+		}
+		current = next
+	}
+
+	if resolved != len(domains) {
+		return nil, fmt.Errorf("domain dependencies contain a cycle")
+	}
+
+	return levels, nil
+}
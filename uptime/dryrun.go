@@ -0,0 +1,211 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// dryRunProbeTimeout bounds each reachability probe made by a dry run
+const dryRunProbeTimeout = 15 * time.Second
+
+// notifierProbe is implemented by notifiers that can check their channel is
+// reachable without raising an alert
+type notifierProbe interface {
+	Probe(ctx context.Context) error
+}
+
+// DryRun prints what a run would do, probes each notification channel and
+// the SMTP login, and returns the exit code: 0 when every probe succeeded, 1
+// otherwise. No checks are run and no report is saved or submitted.
+func (m *UptimeMonitor) DryRun(out io.Writer) int {
+	config := m.config
+	failed := false
+
+	fmt.Fprintln(out, "Dry run: configuration is valid")
+	fmt.Fprintf(out, "\nDomains (%d):\n", len(config.Domains))
+	for _, domain := range config.Domains {
+		fmt.Fprintf(out, "  - %s\n", domain)
+	}
+
+	mode := "single run"
+	if config.Interval > 0 {
+		mode = fmt.Sprintf("daemon, every %s", config.Interval)
+	}
+	fmt.Fprintf(out, "\nMode: %s, %d concurrent checks, %s timeout\n",
+		mode, m.concurrency(len(config.Domains)), config.Timeout)
+	fmt.Fprintf(out, "Reports: %s in %s\n", strings.Join(config.ReportFormats, ", "), config.OutputDir)
+	fmt.Fprintf(out, "Chart storage: %s\n", config.ChartStorage)
+	if config.ExitPolicy == ExitPolicyUptime {
+		fmt.Fprintf(out, "Exit policy: uptime below %g%%\n", config.MinUptimePercent)
+	} else {
+		fmt.Fprintf(out, "Exit policy: %s\n", config.ExitPolicy)
+	}
+
+	fmt.Fprintln(out, "\nAPI targets:")
+	if len(config.APITargets) == 0 {
+		fmt.Fprintln(out, "  (none)")
+	}
+	for _, target := range config.APITargets {
+		fmt.Fprintf(out, "  - %s: %s %s\n", target.Name, target.Method, displayURL(target.URL))
+	}
+
+	fmt.Fprintln(out, "\nNotifiers:")
+	if config.NotificationsDisabled {
+		fmt.Fprintln(out, "  (notifications disabled by NOTIFICATIONS_DISABLED)")
+	}
+	if len(m.notifiers) == 0 {
+		fmt.Fprintln(out, "  (none)")
+	}
+	for _, n := range m.notifiers {
+		probe, ok := n.(notifierProbe)
+		if !ok {
+			fmt.Fprintf(out, "  - %s: configured, not probed\n", n.Name())
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), dryRunProbeTimeout)
+		err := probe.Probe(ctx)
+		cancel()
+		if err != nil {
+			failed = true
+			fmt.Fprintf(out, "  - %s: FAILED: %v\n", n.Name(), err)
+			continue
+		}
+		fmt.Fprintf(out, "  - %s: ok\n", n.Name())
+	}
+
+	fmt.Fprintln(out, "\nEmail:")
+	groups := m.emailGroups()
+	if !m.emailConfigured(groups) {
+		fmt.Fprintln(out, "  (not configured)")
+	} else {
+		for _, group := range groups {
+			fmt.Fprintf(out, "  - %s: %s\n", group.Name, strings.Join(group.recipients(), ", "))
+		}
+		if err := m.verifySMTPLogin(); err != nil {
+			failed = true
+			fmt.Fprintf(out, "  SMTP login to %s:%s FAILED: %v\n", config.SMTPHost, config.SMTPPort, err)
+		} else {
+			fmt.Fprintf(out, "  SMTP login to %s:%s ok\n", config.SMTPHost, config.SMTPPort)
+		}
+	}
+
+	if failed {
+		fmt.Fprintln(out, "\nDry run failed: one or more channels could not be reached")
+		return 1
+	}
+	fmt.Fprintln(out, "\nDry run passed")
+	return 0
+}
+
+// verifySMTPLogin connects to the SMTP server and authenticates without
+// sending a message
+func (m *UptimeMonitor) verifySMTPLogin() error {
+	client, err := dialSMTP(m.config.SMTPHost, m.config.SMTPPort, m.config.SMTPTLSMode)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	auth, err := m.smtpAuth()
+	if err != nil {
+		return err
+	}
+	if err := client.Auth(auth); err != nil {
+		if xa, ok := auth.(*xoauth2Auth); ok {
+			return xa.authError(err)
+		}
+		return fmt.Errorf("SMTP authentication failed: %w", err)
+	}
+	return client.Quit()
+}
+
+// probeRequest makes a single request without retries and fails on any
+// non-2xx status
+func (m *UptimeMonitor) probeRequest(ctx context.Context, method, target string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return unwrapURLError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Probe posts a short test message; Slack incoming webhooks offer no way to
+// check a URL without posting to the channel
+func (n *slackNotifier) Probe(ctx context.Context) error {
+	body := fmt.Sprintf(`{"text":"Uptime monitor dry run from %s: Slack notifications are working"}`, dryRunHost())
+	return n.monitor.probeRequest(ctx, http.MethodPost, n.url, strings.NewReader(body))
+}
+
+// Probe fetches the webhook's metadata, which posts nothing to the channel
+func (n *discordNotifier) Probe(ctx context.Context) error {
+	return n.monitor.probeRequest(ctx, http.MethodGet, n.url, nil)
+}
+
+// Probe looks up the chat, checking both the bot token and that the bot can
+// see the chat, without sending a message
+func (n *telegramNotifier) Probe(ctx context.Context) error {
+	target := fmt.Sprintf("%s/bot%s/getChat?chat_id=%s", telegramAPIBase, n.token, url.QueryEscape(n.chatID))
+	return n.monitor.probeRequest(ctx, http.MethodGet, target, nil)
+}
+
+// Probe renders the template against a sample report; the endpoint itself is
+// not called, since any request to it would be taken as a real alert
+func (n *webhookNotifier) Probe(ctx context.Context) error {
+	sample := &MonitorReport{
+		Timestamp:   time.Now(),
+		Environment: n.monitor.config.Environment,
+		TotalChecks: len(n.monitor.config.Domains),
+	}
+	for _, domain := range n.monitor.config.Domains {
+		sample.Results = append(sample.Results, HealthCheckResult{Domain: domain, Status: StatusUp})
+	}
+	_, err := renderWebhookTemplate(n.config.Template, sample)
+	return err
+}
+
+// displayURL strips credentials and the query string, either of which may
+// hold a secret, from a URL before it is printed
+func displayURL(raw string) string {
+	raw, _, _ = strings.Cut(raw, "?")
+	// Cut the userinfo out of the raw string rather than re-encoding the
+	// URL, which would escape template actions such as {{.Environment}}
+	if scheme, rest, ok := strings.Cut(raw, "://"); ok {
+		authority, path, _ := strings.Cut(rest, "/")
+		if at := strings.LastIndex(authority, "@"); at >= 0 {
+			raw = scheme + "://" + authority[at+1:]
+			if strings.Contains(rest, "/") {
+				raw += "/" + path
+			}
+		}
+	}
+	return raw
+}
+
+// dryRunHost names the machine in test messages so they can be traced back
+func dryRunHost() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		return "unknown host"
+	}
+	return host
+}
@@ -0,0 +1,29 @@
+package uptime
+
+// EXIT_POLICY values: what makes a run exit with status 1
+const (
+	ExitPolicyDown     = "down"     // any domain is down
+	ExitPolicyDegraded = "degraded" // any domain is down or degraded
+	ExitPolicyUptime   = "uptime"   // uptime is below MIN_UPTIME_PERCENT
+)
+
+// ExitCode returns the process exit code for a report under EXIT_POLICY: 1
+// when the policy is breached, 0 otherwise
+func (c *MonitorConfig) ExitCode(report *MonitorReport) int {
+	failed := false
+	switch c.ExitPolicy {
+	case ExitPolicyDegraded:
+		failed = report.Downtime > 0 || report.Degraded > 0
+	case ExitPolicyUptime:
+		// A run where nothing was counted (every domain in maintenance or
+		// blocked) reports 0% uptime but breaches nothing
+		failed = report.Downtime+report.Degraded > 0 && report.UptimePercent < c.MinUptimePercent
+	default:
+		failed = report.Downtime > 0
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
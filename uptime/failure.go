@@ -0,0 +1,64 @@
+package uptime
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// Failure reasons recorded in HealthCheckResult.FailureReason when a request
+// gets no response
+const (
+	FailureTimeout           = "timeout"
+	FailureConnectionRefused = "connection_refused"
+	FailureConnectionReset   = "connection_reset"
+	FailureDNS               = "dns_failure"
+	FailureTLS               = "tls_error"
+	FailureCancelled         = "cancelled"
+	FailureOther             = "other"
+)
+
+// classifyFailure sorts a transport error into one of the Failure* reasons,
+// or "" when err is nil
+func classifyFailure(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	// Cancellation comes first: a cancelled dial also looks like a net.OpError
+	if errors.Is(err, context.Canceled) {
+		return FailureCancelled
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return FailureDNS
+	}
+
+	if classifyTLSError(err) != "" {
+		return FailureTLS
+	}
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) {
+		return FailureTLS
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return FailureConnectionRefused
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return FailureConnectionReset
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return FailureTimeout
+	}
+
+	return FailureOther
+}
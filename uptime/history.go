@@ -0,0 +1,201 @@
+package uptime
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const DefaultHistorySize = 50
+
+// HistoryCache is a fixed-size ring buffer of the most recent reports. It is
+// safe for concurrent use and lets stateful features (status changes, streaks,
+// rolling uptime) read prior runs without reloading them from disk each cycle.
+type HistoryCache struct {
+	mu      sync.RWMutex
+	reports []*MonitorReport
+	next    int
+	count   int
+	path    string
+	breaker *CircuitBreaker // persisted alongside the reports when set
+}
+
+// historySnapshot is the on-disk representation of the cache
+type historySnapshot struct {
+	Reports  []*MonitorReport        `json:"reports"`
+	Breakers map[string]breakerState `json:"breakers,omitempty"`
+}
+
+// NewHistoryCache creates a cache holding up to size reports, persisted at path
+func NewHistoryCache(size int, path string) *HistoryCache {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &HistoryCache{
+		reports: make([]*MonitorReport, size),
+		path:    path,
+	}
+}
+
+// Add records a report, evicting the oldest one when the buffer is full
+func (h *HistoryCache) Add(report *MonitorReport) {
+	if report == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.reports[h.next] = report
+	h.next = (h.next + 1) % len(h.reports)
+	if h.count < len(h.reports) {
+		h.count++
+	}
+}
+
+// Latest returns the most recently added report, or nil when the cache is empty
+func (h *HistoryCache) Latest() *MonitorReport {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.count == 0 {
+		return nil
+	}
+	return h.reports[(h.next-1+len(h.reports))%len(h.reports)]
+}
+
+// Reports returns the cached reports ordered from oldest to newest
+func (h *HistoryCache) Reports() []*MonitorReport {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.ordered()
+}
+
+// ordered returns the reports oldest first; the caller must hold the lock
+func (h *HistoryCache) ordered() []*MonitorReport {
+	out := make([]*MonitorReport, 0, h.count)
+	start := (h.next - h.count + len(h.reports)) % len(h.reports)
+	for i := 0; i < h.count; i++ {
+		out = append(out, h.reports[(start+i)%len(h.reports)])
+	}
+	return out
+}
+
+// Streak returns the domain's most recent status and how many consecutive
+// cached runs it has held that status for
+func (h *HistoryCache) Streak(domain string) (string, int) {
+	reports := h.Reports()
+
+	var status string
+	count := 0
+	for i := len(reports) - 1; i >= 0; i-- {
+		result, ok := findResult(reports[i], domain)
+		if !ok {
+			break
+		}
+		if count == 0 {
+			status = result.Status
+		} else if result.Status != status {
+			break
+		}
+		count++
+	}
+
+	return status, count
+}
+
+// FailingSince returns the timestamp of the earliest run in the domain's most
+// recent streak of down or degraded results, or the zero time if its latest
+// cached result isn't failing
+func (h *HistoryCache) FailingSince(domain string) time.Time {
+	reports := h.Reports()
+
+	var since time.Time
+	for i := len(reports) - 1; i >= 0; i-- {
+		result, ok := findResult(reports[i], domain)
+		if !ok || (result.Status != StatusDown && result.Status != StatusDegraded) {
+			break
+		}
+		since = reports[i].Timestamp
+	}
+
+	return since
+}
+
+// Load restores the cache from disk. A missing file is not an error.
+func (h *HistoryCache) Load() error {
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var snapshot historySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse history file: %w", err)
+	}
+
+	for _, report := range snapshot.Reports {
+		h.Add(report)
+	}
+	if h.breaker != nil && snapshot.Breakers != nil {
+		h.breaker.restore(snapshot.Breakers)
+	}
+	return nil
+}
+
+// Save writes the cache to disk, replacing the previous file atomically
+func (h *HistoryCache) Save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	h.mu.RLock()
+	snapshot := historySnapshot{Reports: h.ordered()}
+	h.mu.RUnlock()
+
+	if h.breaker != nil {
+		snapshot.Breakers = h.breaker.snapshot()
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+	return nil
+}
+
+// findResult returns the result for domain within report
+func findResult(report *MonitorReport, domain string) (HealthCheckResult, bool) {
+	if report == nil {
+		return HealthCheckResult{}, false
+	}
+	for _, result := range report.Results {
+		if result.Domain == domain {
+			return result, true
+		}
+	}
+	return HealthCheckResult{}, false
+}
@@ -0,0 +1,67 @@
+package uptime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"go.uber.org/zap"
+)
+
+// MaxHookOutput caps how much of the post-run command's output is logged
+const MaxHookOutput = 4096
+
+// RunPostCommand executes POST_RUN_COMMAND through the shell with the report
+// JSON on stdin and key metrics in the environment. The command is bound to
+// ctx, so it is killed when the run deadline expires.
+func (m *UptimeMonitor) RunPostCommand(ctx context.Context, report *MonitorReport) error {
+	if m.config.PostRunCommand == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", m.config.PostRunCommand)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("UPTIME_PERCENT=%.2f", report.UptimePercent),
+		fmt.Sprintf("TOTAL_CHECKS=%d", report.TotalChecks),
+		fmt.Sprintf("UPTIME=%d", report.Uptime),
+		fmt.Sprintf("DOWNTIME=%d", report.Downtime),
+		fmt.Sprintf("DEGRADED=%d", report.Degraded),
+		fmt.Sprintf("ENVIRONMENT=%s", report.Environment),
+		fmt.Sprintf("REPORT_FILE=%s", report.ReportFile),
+	)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+
+	out := output.String()
+	if len(out) > MaxHookOutput {
+		out = out[:MaxHookOutput] + "... (truncated)"
+	}
+
+	exitCode := 0
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	m.logger.Info("Post-run command finished",
+		zap.String("command", m.config.PostRunCommand),
+		zap.Int("exit_code", exitCode),
+		zap.String("output", out))
+
+	if err != nil {
+		return fmt.Errorf("post-run command failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,482 @@
+package uptime
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	EmailFormatFull    = "full"    // HTML report plus JSON data
+	EmailFormatSummary = "summary" // single plain-text summary line
+
+	DefaultHTMLMaxRows = 100
+
+	DefaultSummaryTemplate = `{{.Service}} ({{.Environment}}): {{printf "%.2f" .UptimePercent}}% uptime, {{.Downtime}} down, {{.Degraded}} degraded of {{.TotalChecks}} checks`
+)
+
+// HTMLReportOptions controls how BuildHTMLReport renders the report
+type HTMLReportOptions struct {
+	MaxRows     int              // cap on detailed result rows; 0 shows every result
+	InlineChart bool             // embed the chart as a data URI instead of uploading it
+	History     []*MonitorReport // earlier runs, oldest first, for the trend chart
+	Chart       ChartOptions     // chart size and colours
+	Storage     ChartStorage     // where PNG charts are stored; nil embeds them
+	Logger      *zap.Logger      // receives chart failures; nil discards them
+}
+
+// logger returns opts.Logger, or a no-op logger when none was given
+func (opts HTMLReportOptions) logger() *zap.Logger {
+	if opts.Logger == nil {
+		return zap.NewNop()
+	}
+	return opts.Logger
+}
+
+// resultsTableHeader is the header row shared by the results tables
+const resultsTableHeader = `<tr>
+            <th>Domain</th><th>Status</th><th>Code</th><th>Latency</th>
+            <th>Timing</th><th>Attempts</th><th>SSL Expiry</th><th>Checked At</th>
+          </tr>`
+
+// EmailGroup is a set of recipients that receive their own message format.
+// Subject and Body are text/template strings executed with the MonitorReport.
+type EmailGroup struct {
+	Name    string   `json:"name"`
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Bcc     []string `json:"bcc,omitempty"`     // receive the message without appearing in headers
+	Format  string   `json:"format,omitempty"`  // full (default) or summary
+	Subject string   `json:"subject,omitempty"` // defaults to the alert subject
+	Body    string   `json:"body,omitempty"`    // plain-text body
+}
+
+// recipients returns every SMTP envelope recipient of the group: To, Cc and Bcc
+func (g EmailGroup) recipients() []string {
+	all := make([]string, 0, len(g.To)+len(g.Cc)+len(g.Bcc))
+	all = append(all, g.To...)
+	all = append(all, g.Cc...)
+	return append(all, g.Bcc...)
+}
+
+// parseEmailGroups decodes EMAIL_GROUPS and validates formats and templates up front
+func parseEmailGroups(raw string) ([]EmailGroup, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var groups []EmailGroup
+	if err := json.Unmarshal([]byte(raw), &groups); err != nil {
+		return nil, fmt.Errorf("invalid EMAIL_GROUPS: %w", err)
+	}
+
+	for i, group := range groups {
+		if group.Name == "" {
+			groups[i].Name = fmt.Sprintf("group %d", i+1)
+		}
+		if len(group.To) == 0 {
+			return nil, fmt.Errorf("invalid EMAIL_GROUPS: %s has no recipients", groups[i].Name)
+		}
+
+		switch group.Format {
+		case "":
+			groups[i].Format = EmailFormatFull
+		case EmailFormatFull, EmailFormatSummary:
+		default:
+			return nil, fmt.Errorf("invalid EMAIL_GROUPS: %s has unknown format %q", groups[i].Name, group.Format)
+		}
+
+		for _, text := range []string{group.Subject, group.Body} {
+			if _, err := template.New(groups[i].Name).Parse(text); err != nil {
+				return nil, fmt.Errorf("invalid EMAIL_GROUPS: %s template: %w", groups[i].Name, err)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// renderEmailTemplate executes text with the report, returning fallback when text is empty
+func renderEmailTemplate(text, fallback string, report *MonitorReport) (string, error) {
+	if text == "" {
+		return fallback, nil
+	}
+
+	tmpl, err := template.New("email").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse email template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("failed to render email template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func BuildHTMLReport(report *MonitorReport, subject string, opts HTMLReportOptions) (string, error) {
+	uptimeChart := ""
+	if encoded, err := generateUptimeChart(report, opts.Chart); err != nil {
+		opts.logger().Warn("Failed to render uptime chart", zap.Error(err))
+	} else {
+		uptimeChart = chartImage(encoded, "Uptime Chart", opts)
+	}
+
+	trendChart := buildTrendChart(report, opts)
+	latencyChart := buildLatencyChart(report, opts)
+
+	rows, truncationNote := limitResults(report, opts.MaxRows)
+
+	html := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>%s</title>
+<style>
+body {
+  font-family: "Segoe UI", Roboto, Arial, sans-serif;
+  background-color: #f8f9fb;
+  margin: 0;
+  color: #333;
+}
+.container {
+  max-width: 850px;
+  margin: 30px auto;
+  background: #fff;
+  border-radius: 10px;
+  box-shadow: 0 3px 10px rgba(0,0,0,0.1);
+  overflow: hidden;
+}
+.header {
+  background: linear-gradient(135deg, #2f2e41, #4a47a3);
+  color: #fff;
+  padding: 20px 30px;
+}
+.header h1 { margin: 0; font-size: 1.6em; }
+.header p {color: #ffffff}
+.section { padding: 20px 30px; }
+h2 { color: #2f2e41; border-bottom: 2px solid #eee; padding-bottom: 5px; }
+.stats {
+  display: flex; flex-wrap: wrap; gap: 15px; margin-top: 10px;
+}
+.stat {
+  flex: 1 1 150px; background: #f5f6f9;
+  padding: 10px; border-radius: 8px; text-align: center;
+}
+.stat span {
+  display: block; font-size: 1.3em; font-weight: bold; color: #2f2e41;
+}
+.table-container { overflow-x: auto; margin-top: 15px; }
+table { width: 100%%; border-collapse: collapse; }
+th, td { padding: 10px; text-align: left; border-bottom: 1px solid #eee; font-size: 0.95em; }
+th { background: #fafafa; font-weight: 600; }
+tr:hover { background: #f9f9ff; }
+.status-up { color: #2ecc71; font-weight: bold; }
+.status-down { color: #e74c3c; font-weight: bold; }
+.status-degraded { color: #f39c12; font-weight: bold; }
+.status-blocked { color: #95a5a6; font-weight: bold; }
+.status-maintenance { color: #3498db; font-weight: bold; }
+.chart {
+  width: 100%%;
+  text-align: center;
+  margin-top: 15px;
+}
+.footer {
+  background: #f4f4f8; color: #777;
+  text-align: center; padding: 15px; font-size: 0.85em;
+}
+pre {
+  background: #1e1e1e; color: #eee; padding: 12px;
+  border-radius: 8px; overflow-x: auto; font-size: 0.9em;
+}
+</style>
+</head>
+<body>
+  <div class="container">
+    <div class="header">
+      <h1>📡 %s</h1>
+      <p>Generated on %s</p>
+    </div>
+
+    <div class="section">
+      <h2>Summary</h2>
+      <div class="stats">
+        <div class="stat"><span>%d</span>Total Checks</div>
+        <div class="stat"><span>%d</span>Uptime</div>
+        <div class="stat"><span>%d</span>Downtime</div>
+        <div class="stat"><span>%d</span>Degraded</div>
+        <div class="stat"><span>%.2f%%</span>Uptime %%</div>
+        <div class="stat"><span>%.2f ms</span>Avg Latency</div>
+        <div class="stat"><span>%.1f</span>Health Score</div>
+      </div>
+      <div class="chart">
+        %s
+      </div>
+%s%s    </div>
+%s
+%s
+    <div class="section">
+      <h2>Detailed Results</h2>
+      <div class="table-container">
+        <table>
+          %s
+          %s
+        </table>
+      </div>%s
+    </div>
+
+    <div class="section">
+      <p>The full report data is attached as <code>%s</code>.</p>
+    </div>
+
+    <div class="footer">
+      <p>Powered by <strong>Axiolot Hub</strong> — Reliable monitoring, elegant delivery.</p>
+    </div>
+  </div>
+</body>
+</html>`,
+		subject,
+		subject,
+		report.Timestamp.Format(time.RFC1123),
+		report.TotalChecks, report.Uptime, report.Downtime, report.Degraded,
+		report.UptimePercent, report.AverageLatency, report.HealthScore,
+		uptimeChart,
+		trendChart,
+		latencyChart,
+		buildChangesSection(report),
+		buildAttentionSection(report.Results),
+		resultsTableHeader,
+		buildResultsTable(rows),
+		truncationNote,
+		reportAttachmentName,
+	)
+
+	return html, nil
+}
+
+// buildTrendChart renders the uptime and latency trend over opts.History
+// and this run, or nothing when there is no earlier run to compare with
+func buildTrendChart(report *MonitorReport, opts HTMLReportOptions) string {
+	runs := make([]*MonitorReport, 0, len(opts.History)+1)
+	for _, previous := range opts.History {
+		if previous != nil && previous.Timestamp.Before(report.Timestamp) {
+			runs = append(runs, previous)
+		}
+	}
+	runs = append(runs, report)
+	if len(runs) < minTrendPoints {
+		return ""
+	}
+
+	encoded, err := generateTrendChart(runs, opts.Chart)
+	if err != nil {
+		opts.logger().Warn("Failed to render trend chart", zap.Error(err))
+		return ""
+	}
+
+	return fmt.Sprintf(`      <div class="chart">
+        %s
+      </div>
+`, chartImage(encoded, "Uptime Trend Chart", opts))
+}
+
+// buildLatencyChart renders the per-domain latency chart, noting how many
+// domains were left out beyond the slowest LatencyChartMaxBars
+func buildLatencyChart(report *MonitorReport, opts HTMLReportOptions) string {
+	encoded, err := generateLatencyChart(report, opts.Chart)
+	if err != nil {
+		opts.logger().Warn("Failed to render latency chart", zap.Error(err))
+		return ""
+	}
+
+	note := ""
+	if omitted := len(report.Results) - LatencyChartMaxBars; omitted > 0 {
+		note = fmt.Sprintf(`
+        <p>Showing the %d slowest domains; %d more omitted.</p>`, LatencyChartMaxBars, omitted)
+	}
+
+	return fmt.Sprintf(`      <div class="chart">
+        %s%s
+      </div>
+`, chartImage(encoded, "Latency by Domain Chart", opts), note)
+}
+
+// chartImage returns the markup for a generated chart. SVG charts are inlined
+// as is, with no upload; PNG charts become an <img> whose source is picked by
+// chartSource.
+func chartImage(encoded, alt string, opts HTMLReportOptions) string {
+	if opts.Chart.Format == ChartFormatSVG {
+		return encoded
+	}
+	return fmt.Sprintf(`<img src="%s" alt="%s" style="max-width: 100%%; border-radius: 8px; margin-top: 10px;">`,
+		chartSource(encoded, opts.InlineChart, opts.Storage, opts.logger()), alt)
+}
+
+// chartSource picks the <img src> for the chart. Inline mode, or having no
+// storage, embeds the PNG as a data URI; otherwise the chart is uploaded and
+// linked, falling back to the data URI if the upload fails, since many email
+// clients strip data URIs.
+func chartSource(pngBase64 string, inline bool, storage ChartStorage, logger *zap.Logger) string {
+	dataURI := "data:image/png;base64," + pngBase64
+	if inline || storage == nil {
+		return dataURI
+	}
+
+	data, err := base64.StdEncoding.DecodeString(pngBase64)
+	if err != nil {
+		logger.Warn("Failed to decode chart, embedding it instead", zap.Error(err))
+		return dataURI
+	}
+
+	link, err := storage.Upload(data)
+	if err != nil {
+		logger.Warn("Failed to upload chart, embedding it instead", zap.Error(err))
+		return dataURI
+	}
+	if link == "" {
+		return dataURI
+	}
+	return link
+}
+
+// statusRank orders statuses failures-first for the HTML report
+func statusRank(status string) int {
+	switch status {
+	case StatusDown:
+		return 0
+	case StatusDegraded:
+		return 1
+	case StatusBlocked:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// limitResults returns the results to show in the detailed table, failures
+// first and capped to maxRows, with a note describing what was left out
+func limitResults(report *MonitorReport, maxRows int) ([]HealthCheckResult, string) {
+	if maxRows <= 0 || len(report.Results) <= maxRows {
+		return report.Results, ""
+	}
+
+	sorted := make([]HealthCheckResult, len(report.Results))
+	copy(sorted, report.Results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return statusRank(sorted[i].Status) < statusRank(sorted[j].Status)
+	})
+
+	location := "the JSON report"
+	if report.ReportFile != "" {
+		location = html.EscapeString(report.ReportFile)
+	}
+
+	note := fmt.Sprintf(`
+      <p>Showing %d of %d results, failures first. The full list is in %s.</p>`,
+		maxRows, len(sorted), location)
+
+	return sorted[:maxRows], note
+}
+
+// buildAttentionSection renders a table of every result that isn't up or in
+// maintenance, or nothing when all checks passed
+func buildAttentionSection(results []HealthCheckResult) string {
+	var failing []HealthCheckResult
+	for _, r := range results {
+		if r.Status != StatusUp && r.Status != StatusMaintenance {
+			failing = append(failing, r)
+		}
+	}
+
+	if len(failing) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(failing, func(i, j int) bool {
+		return statusRank(failing[i].Status) < statusRank(failing[j].Status)
+	})
+
+	return fmt.Sprintf(`
+    <div class="section">
+      <h2>Needs Attention (%d)</h2>
+      <div class="table-container">
+        <table>
+          %s
+          %s
+        </table>
+      </div>
+    </div>
+`, len(failing), resultsTableHeader, buildResultsTable(failing))
+}
+
+func buildResultsTable(results []HealthCheckResult) string {
+	rows := ""
+	for _, r := range results {
+		statusClass := "status-up"
+		if strings.ToLower(r.Status) == "down" {
+			statusClass = "status-down"
+		} else if strings.ToLower(r.Status) == "degraded" {
+			statusClass = "status-degraded"
+		} else if strings.ToLower(r.Status) == "blocked" {
+			statusClass = "status-blocked"
+		} else if strings.ToLower(r.Status) == "maintenance" {
+			statusClass = "status-maintenance"
+		}
+		// The final URL comes from a Location header the checked server
+		// controls, so it is escaped like the domain
+		domain := html.EscapeString(r.Domain)
+		if r.FinalURL != "" && r.FinalURL != r.URL {
+			domain += fmt.Sprintf(`<br><small>&rarr; %s</small>`, html.EscapeString(r.FinalURL))
+		}
+		rows += fmt.Sprintf(`
+<tr>
+	<td>%s</td>
+	<td class="%s">%s</td>
+	<td>%d</td>
+	<td>%d ms</td>
+	<td><small>%s</small></td>
+	<td>%s</td>
+	<td>%s</td>
+	<td>%s</td>
+</tr>`, domain, statusClass, strings.ToUpper(r.Status), r.StatusCode, r.ResponseTime, timingBreakdown(r), attemptsCell(r), r.SSLExpiry, r.CheckedAt)
+	}
+	return rows
+}
+
+// attemptsCell shows how many attempts a check took, with the time spent
+// backing off when it was retried
+func attemptsCell(r HealthCheckResult) string {
+	if r.Attempts <= 1 {
+		return strconv.Itoa(r.Attempts)
+	}
+	return fmt.Sprintf("%d<br><small>%d ms backoff</small>", r.Attempts, r.RetryDelayTotal)
+}
+
+// timingBreakdown lists the request phases that applied to the check
+func timingBreakdown(r HealthCheckResult) string {
+	var parts []string
+	for _, phase := range []struct {
+		name string
+		ms   int64
+	}{
+		{"DNS", r.DNSTime},
+		{"Connect", r.ConnectTime},
+		{"TLS", r.TLSTime},
+		{"TTFB", r.TimeToFirstByte},
+	} {
+		if phase.ms > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d ms", phase.name, phase.ms))
+		}
+	}
+	return strings.Join(parts, "<br>")
+}
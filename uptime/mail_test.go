@@ -0,0 +1,127 @@
+package uptime
+
+import (
+	"bytes"
+	"errors"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// stubChartStorage returns link and err from every Upload
+type stubChartStorage struct {
+	link  string
+	err   error
+	calls int
+}
+
+func (s *stubChartStorage) Upload(data []byte) (string, error) {
+	s.calls++
+	return s.link, s.err
+}
+
+func TestChartSource(t *testing.T) {
+	const encoded = "iVBORw0KGgo="
+	const dataURI = "data:image/png;base64," + encoded
+	const link = "https://charts.example.com/chart.png"
+
+	tests := []struct {
+		name      string
+		inline    bool
+		storage   *stubChartStorage
+		want      string
+		wantCalls int
+	}{
+		{name: "inline", inline: true, storage: &stubChartStorage{link: link}, want: dataURI},
+		{name: "nil storage", want: dataURI},
+		{name: "upload error", storage: &stubChartStorage{err: ErrChartUploadFailed}, want: dataURI, wantCalls: 1},
+		{name: "other error", storage: &stubChartStorage{err: errors.New("boom")}, want: dataURI, wantCalls: 1},
+		{name: "empty link", storage: &stubChartStorage{}, want: dataURI, wantCalls: 1},
+		{name: "uploaded", storage: &stubChartStorage{link: link}, want: link, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var storage ChartStorage
+			if tt.storage != nil {
+				storage = tt.storage
+			}
+			if got := chartSource(encoded, tt.inline, storage, zap.NewNop()); got != tt.want {
+				t.Errorf("chartSource() = %q, want %q", got, tt.want)
+			}
+			if tt.storage != nil && tt.storage.calls != tt.wantCalls {
+				t.Errorf("Upload called %d times, want %d", tt.storage.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEmailBccStaysOutOfHeaders(t *testing.T) {
+	m := &UptimeMonitor{config: &MonitorConfig{
+		EmailTo:  []string{"ops@example.com"},
+		EmailCC:  []string{"lead@example.com"},
+		EmailBCC: []string{"audit@example.com"},
+	}}
+	group := m.emailGroups()[0]
+
+	raw := BuildEmailMessage("monitor@example.com", group.To, group.Cc, "Report", "<p>report</p>", "report")
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage() error: %v", err)
+	}
+
+	if got := msg.Header.Get("Cc"); got != "lead@example.com" {
+		t.Errorf("Cc header = %q, want %q", got, "lead@example.com")
+	}
+	for name, values := range msg.Header {
+		if strings.EqualFold(name, "Bcc") {
+			t.Errorf("message has a Bcc header: %v", values)
+		}
+		for _, value := range values {
+			if strings.Contains(value, "audit@example.com") {
+				t.Errorf("header %s exposes the Bcc address: %q", name, value)
+			}
+		}
+	}
+
+	want := []string{"ops@example.com", "lead@example.com", "audit@example.com"}
+	if got := group.recipients(); !reflect.DeepEqual(got, want) {
+		t.Errorf("recipients() = %v, want %v", got, want)
+	}
+}
+
+func TestEmailAlertsRegistersNotifier(t *testing.T) {
+	t.Setenv("MONITOR_DOMAINS", "example.com")
+	t.Setenv("EMAIL_USER", "monitor@example.com")
+	t.Setenv("EMAIL_AUTH", "secret")
+	t.Setenv("EMAIL_TO", "ops@example.com")
+
+	hasEmail := func() bool {
+		config, err := NewMonitorConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		monitor := NewUptimeMonitor(config, zap.NewNop())
+		for _, n := range monitor.notifiers {
+			if n.Name() == "email" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasEmail() {
+		t.Fatal("email notifier registered without EMAIL_ALERTS")
+	}
+	t.Setenv("EMAIL_ALERTS", "true")
+	if !hasEmail() {
+		t.Fatal("email notifier not registered with EMAIL_ALERTS=true")
+	}
+	t.Setenv("EMAIL_AUTH", "")
+	if hasEmail() {
+		t.Fatal("email notifier registered without email credentials")
+	}
+}
@@ -0,0 +1,203 @@
+package uptime
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a period of planned work. Checks of a covered domain
+// still run, but the result is reported as maintenance: it doesn't count
+// towards downtime or uptime and raises no alerts.
+//
+// A window is either one-off, from Start to End, or recurring, covering the
+// Daily "HH:MM-HH:MM" range in Timezone (UTC by default) on the listed Days
+// (every day when empty). A Daily range may wrap past midnight.
+type MaintenanceWindow struct {
+	Name     string    `json:"name,omitempty"`
+	Start    time.Time `json:"start,omitempty"`    // RFC 3339
+	End      time.Time `json:"end,omitempty"`      // RFC 3339
+	Daily    string    `json:"daily,omitempty"`    // e.g. 02:00-03:00
+	Days     []string  `json:"days,omitempty"`     // mon, tue, ... for weekly windows
+	Timezone string    `json:"timezone,omitempty"` // IANA name, e.g. Europe/Berlin
+	Domains  []string  `json:"domains,omitempty"`  // MAINTENANCE_WINDOWS only; empty covers every domain
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseMaintenanceWindows reads MAINTENANCE_WINDOWS, a JSON array of windows
+func parseMaintenanceWindows(raw string) ([]MaintenanceWindow, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var windows []MaintenanceWindow
+	if err := json.Unmarshal([]byte(raw), &windows); err != nil {
+		return nil, fmt.Errorf("invalid MAINTENANCE_WINDOWS: %w", err)
+	}
+	for i, w := range windows {
+		if err := w.validate(); err != nil {
+			return nil, fmt.Errorf("invalid MAINTENANCE_WINDOWS entry %d: %w", i, err)
+		}
+	}
+	return windows, nil
+}
+
+// label names the window in results and logs
+func (w MaintenanceWindow) label() string {
+	if w.Name != "" {
+		return w.Name
+	}
+	if w.Daily != "" {
+		return "daily " + w.Daily
+	}
+	return w.Start.Format(time.RFC3339) + "/" + w.End.Format(time.RFC3339)
+}
+
+// validate checks that the window is either one-off or recurring and that
+// its times parse
+func (w MaintenanceWindow) validate() error {
+	oneOff := !w.Start.IsZero() || !w.End.IsZero()
+	switch {
+	case oneOff && w.Daily != "":
+		return fmt.Errorf("set either start/end or daily, not both")
+	case oneOff:
+		if w.Start.IsZero() || w.End.IsZero() || !w.End.After(w.Start) {
+			return fmt.Errorf("start and end must both be set, with end after start")
+		}
+		if len(w.Days) > 0 || w.Timezone != "" {
+			return fmt.Errorf("days and timezone only apply to daily windows")
+		}
+	case w.Daily != "":
+		if _, _, err := parseDailyRange(w.Daily); err != nil {
+			return err
+		}
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", w.Timezone)
+		}
+		for _, day := range w.Days {
+			if _, ok := weekdayNames[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("unknown day %q, want mon, tue, wed, thu, fri, sat or sun", day)
+			}
+		}
+	default:
+		return fmt.Errorf("set start and end, or daily")
+	}
+	return nil
+}
+
+// parseDailyRange parses "HH:MM-HH:MM" into minutes after midnight
+func parseDailyRange(daily string) (from, to int, err error) {
+	start, end, ok := strings.Cut(daily, "-")
+	if ok {
+		from, err = parseClock(strings.TrimSpace(start))
+	}
+	if ok && err == nil {
+		to, err = parseClock(strings.TrimSpace(end))
+	}
+	if !ok || err != nil || from == to {
+		return 0, 0, fmt.Errorf("daily must be a range like 02:00-03:00, got %q", daily)
+	}
+	return from, to, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t falls inside the window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	if w.Daily == "" {
+		return !t.Before(w.Start) && t.Before(w.End)
+	}
+
+	from, to, err := parseDailyRange(w.Daily)
+	if err != nil {
+		return false
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	switch {
+	case from < to:
+		if minute < from || minute >= to {
+			return false
+		}
+	case minute >= from:
+		// Before midnight in a range that wraps
+	case minute < to:
+		// After midnight: the window opened the day before
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+	return w.onDay(day)
+}
+
+// onDay reports whether a recurring window opens on day
+func (w MaintenanceWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if weekdayNames[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether a MAINTENANCE_WINDOWS entry applies to domain
+func (w MaintenanceWindow) covers(domain string) bool {
+	if len(w.Domains) == 0 {
+		return true
+	}
+	for _, d := range w.Domains {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeMaintenance returns the window domain is in at now, checking the
+// domain's own windows before the global ones
+func (m *UptimeMonitor) activeMaintenance(domain string, now time.Time) (MaintenanceWindow, bool) {
+	for _, w := range m.domainSettings(domain).Maintenance {
+		if w.Contains(now) {
+			return w, true
+		}
+	}
+	for _, w := range m.config.MaintenanceWindows {
+		if w.covers(domain) && w.Contains(now) {
+			return w, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// applyMaintenance marks result as maintenance when its domain is in a
+// window, keeping what the check observed in ObservedStatus
+func (m *UptimeMonitor) applyMaintenance(result *HealthCheckResult, now time.Time) bool {
+	w, ok := m.activeMaintenance(result.Domain, now)
+	if !ok {
+		return false
+	}
+	result.ObservedStatus = result.Status
+	result.Status = StatusMaintenance
+	result.MaintenanceWindow = w.label()
+	return true
+}
@@ -0,0 +1,165 @@
+package uptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// prometheusLabelEscaper escapes label values for the text exposition format
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// RenderPrometheus renders the report in the Prometheus text exposition
+// format, one series per domain plus run-wide aggregates
+func RenderPrometheus(report *MonitorReport) string {
+	var b strings.Builder
+
+	env := prometheusLabelEscaper.Replace(report.Environment)
+	labels := func(domain string) string {
+		return fmt.Sprintf(`{domain="%s",environment="%s"}`, prometheusLabelEscaper.Replace(domain), env)
+	}
+
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("uptime_check_up", "Whether the last check of the domain was up (1) or not (0).")
+	for _, r := range report.Results {
+		up := 0
+		if r.Status == StatusUp {
+			up = 1
+		}
+		fmt.Fprintf(&b, "uptime_check_up%s %d\n", labels(r.Domain), up)
+	}
+
+	gauge("uptime_check_degraded", "Whether the last check of the domain was degraded (1) or not (0).")
+	for _, r := range report.Results {
+		degraded := 0
+		if r.Status == StatusDegraded {
+			degraded = 1
+		}
+		fmt.Fprintf(&b, "uptime_check_degraded%s %d\n", labels(r.Domain), degraded)
+	}
+
+	gauge("uptime_check_latency_ms", "Response time of the last check in milliseconds.")
+	for _, r := range report.Results {
+		fmt.Fprintf(&b, "uptime_check_latency_ms%s %d\n", labels(r.Domain), r.ResponseTime)
+	}
+
+	gauge("uptime_check_status_code", "HTTP status code of the last check (0 when no response was received, and for TCP checks).")
+	for _, r := range report.Results {
+		fmt.Fprintf(&b, "uptime_check_status_code%s %d\n", labels(r.Domain), r.StatusCode)
+	}
+
+	gauge("uptime_ssl_days_left", "Days until the domain's TLS certificate expires.")
+	for _, r := range report.Results {
+		if r.IsSSL && r.SSLExpiry != "" {
+			fmt.Fprintf(&b, "uptime_ssl_days_left%s %d\n", labels(r.Domain), r.SSLDaysLeft)
+		}
+	}
+
+	runLabels := fmt.Sprintf(`{environment="%s"}`, env)
+
+	gauge("uptime_uptime_percent", "Percentage of checks that were up in the last run.")
+	fmt.Fprintf(&b, "uptime_uptime_percent%s %g\n", runLabels, report.UptimePercent)
+
+	gauge("uptime_health_score", "Composite 0-100 health score of the last run.")
+	fmt.Fprintf(&b, "uptime_health_score%s %g\n", runLabels, report.HealthScore)
+
+	gauge("uptime_checks", "Checks in the last run by status.")
+	for _, c := range []struct {
+		status string
+		count  int
+	}{{StatusUp, report.Uptime}, {StatusDown, report.Downtime}, {StatusDegraded, report.Degraded}, {StatusMaintenance, report.Maintenance}} {
+		fmt.Fprintf(&b, "uptime_checks{environment=\"%s\",status=\"%s\"} %d\n", env, c.status, c.count)
+	}
+
+	gauge("uptime_run_duration_seconds", "Time the last run spent checking domains, in seconds.")
+	fmt.Fprintf(&b, "uptime_run_duration_seconds%s %g\n", runLabels, float64(report.RunDuration)/1000)
+
+	gauge("uptime_last_run_timestamp_seconds", "Unix time the last run finished.")
+	fmt.Fprintf(&b, "uptime_last_run_timestamp_seconds%s %d\n", runLabels, report.Timestamp.Unix())
+
+	return b.String()
+}
+
+// metricsStore holds the latest rendered metrics for the HTTP endpoint
+type metricsStore struct {
+	mu   sync.RWMutex
+	text string
+}
+
+func (s *metricsStore) set(text string) {
+	s.mu.Lock()
+	s.text = text
+	s.mu.Unlock()
+}
+
+func (s *metricsStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	text := s.text
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, text)
+}
+
+// ExportMetrics renders the report and publishes it to the metrics file and
+// the HTTP endpoint, whichever are configured
+func (m *UptimeMonitor) ExportMetrics(report *MonitorReport) error {
+	if m.config.MetricsFile == "" && m.config.MetricsAddr == "" {
+		return nil
+	}
+
+	text := RenderPrometheus(report)
+	m.metrics.set(text)
+
+	if m.config.MetricsFile == "" {
+		return nil
+	}
+
+	// node_exporter may read at any moment, so replace the file atomically
+	if err := os.MkdirAll(filepath.Dir(m.config.MetricsFile), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	tmp := m.config.MetricsFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := os.Rename(tmp, m.config.MetricsFile); err != nil {
+		return fmt.Errorf("failed to replace metrics file: %w", err)
+	}
+	return nil
+}
+
+// serveMetrics serves /metrics on MetricsAddr until ctx is done
+func (m *UptimeMonitor) serveMetrics(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", &m.metrics)
+
+	server := &http.Server{
+		Addr:              m.config.MetricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	m.logger.Info("Serving Prometheus metrics", zap.String("addr", m.config.MetricsAddr))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		m.logger.Error("Metrics server failed", zap.Error(err))
+	}
+}
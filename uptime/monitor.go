@@ -0,0 +1,2621 @@
+// Package uptime checks the health of HTTP and TCP endpoints and builds a
+// report of the results, which can be saved, submitted to an API and sent to
+// notification channels. The uptime-monitor command wraps it for scheduled
+// runs; other programs can call RunCheck directly and use the report in
+// memory.
+package uptime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+	StatusBlocked  = "blocked"
+	// StatusMaintenance marks a check run inside a maintenance window
+	StatusMaintenance = "maintenance"
+
+	ThresholdFast    = 1000
+	ThresholdAccept  = 3000
+	SSLExpiryWarning = 30
+
+	// FlakyAttempts is the attempt count at which a check that eventually
+	// succeeded is reported as flaky
+	FlakyAttempts = 2
+
+	DefaultTimeout    = 30 * time.Second
+	DefaultUserAgent  = "Monitoring Client/1.0"
+	DefaultConcurrent = 5
+	DefaultScheme     = "https"
+
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+
+	MaxRetries        = 3
+	InitialBackoff    = 1 * time.Second
+	MaxBackoff        = 30 * time.Second
+	BackoffMultiplier = 2.0
+
+	RequestsPerSecond = 10
+	BurstSize         = 20
+	DefaultSMTPHost   = "smtp.gmail.com"
+)
+
+// HealthCheckResult is the outcome of checking one domain
+type HealthCheckResult struct {
+	Domain            string       `json:"domain"`
+	URL               string       `json:"url"`
+	Method            string       `json:"method,omitempty"`
+	Timeout           int64        `json:"timeout_ms,omitempty"` // effective request timeout for the check
+	FinalURL          string       `json:"final_url,omitempty"`  // URL after redirects
+	Status            string       `json:"status"`
+	StatusCode        int          `json:"status_code"`               // 0 when no response was received, and always for TCP checks
+	ExpectedStatus    []int        `json:"expected_status,omitempty"` // configured codes the check must return
+	MatchedKeyword    *bool        `json:"matched_keyword,omitempty"` // whether the body contained the configured body_match
+	ResponseTime      int64        `json:"response_time_ms"`
+	DNSTime           int64        `json:"dns_time_ms,omitempty"`     // zero when no lookup was needed
+	ConnectTime       int64        `json:"connect_time_ms,omitempty"` // zero when a kept-alive connection was reused
+	TLSTime           int64        `json:"tls_time_ms,omitempty"`     // zero for http:// or reused connections
+	Samples           int          `json:"samples,omitempty"`         // latency samples taken when SAMPLES_PER_CHECK > 1
+	LatencyMin        int64        `json:"latency_min_ms,omitempty"`
+	LatencyMedian     int64        `json:"latency_median_ms,omitempty"`
+	LatencyP95        int64        `json:"latency_p95_ms,omitempty"`
+	LatencyMax        int64        `json:"latency_max_ms,omitempty"`
+	FastThreshold     int64        `json:"fast_threshold_ms,omitempty"` // effective thresholds the status was judged against
+	DegradedThreshold int64        `json:"degraded_threshold_ms,omitempty"`
+	DownThreshold     int64        `json:"down_threshold_ms,omitempty"`
+	TimeToFirstByte   int64        `json:"time_to_first_byte_ms,omitempty"`
+	TimeToLastByte    int64        `json:"time_to_last_byte_ms,omitempty"` // includes draining the body
+	IsSSL             bool         `json:"is_ssl"`                         // always false for TCP checks
+	SSLExpiry         string       `json:"ssl_expiry,omitempty"`
+	SSLDaysLeft       int          `json:"ssl_days_left,omitempty"`
+	CertDNSNames      []string     `json:"cert_dns_names,omitempty"` // SANs of the leaf certificate
+	HostnameMatch     *bool        `json:"hostname_match,omitempty"` // whether the leaf certificate covers the checked hostname
+	TLSError          string       `json:"tls_error,omitempty"`      // certificate validation failure, as opposed to a connectivity error
+	Insecure          bool         `json:"insecure,omitempty"`       // certificate verification was disabled for this check
+	ErrorMessage      string       `json:"error_message,omitempty"`
+	FailureReason     string       `json:"failure_reason,omitempty"`     // timeout, connection_refused, dns_failure, ... when no response was received
+	MaintenanceWindow string       `json:"maintenance_window,omitempty"` // window the check ran in, when status is maintenance
+	ObservedStatus    string       `json:"observed_status,omitempty"`    // what a maintenance check actually saw
+	ContentLength     int64        `json:"content_length"`
+	Attempts          int          `json:"attempts"`
+	RetryDelayTotal   int64        `json:"retry_delay_total_ms,omitempty"` // time spent in backoff between attempts
+	WarmedUp          bool         `json:"warmed_up,omitempty"`            // an untimed warm-up request preceded the check
+	SourceAddress     string       `json:"source_address,omitempty"`
+	ResolvedIPs       []string     `json:"resolved_ips,omitempty"` // set when RESOLVE_DNS is enabled
+	DNSResolveTime    int64        `json:"dns_resolve_time_ms,omitempty"`
+	BlockedBy         string       `json:"blocked_by,omitempty"`
+	CircuitOpen       bool         `json:"circuit_open,omitempty"`
+	Group             string       `json:"group,omitempty"` // SRV entry the target was resolved from
+	Steps             []StepResult `json:"steps,omitempty"`
+	FailedStep        string       `json:"failed_step,omitempty"`
+	HostHeader        string       `json:"host_header,omitempty"`
+	ServerName        string       `json:"server_name,omitempty"` // TLS SNI sent when overridden
+	TLSVersion        string       `json:"tls_version,omitempty"` // negotiated protocol version
+	CipherSuite       string       `json:"cipher_suite,omitempty"`
+	Timestamp         time.Time    `json:"timestamp"`
+	CheckedAt         string       `json:"checked_at"`
+}
+
+// MonitorReport holds the results of one run and their totals
+type MonitorReport struct {
+	Service        string              `json:"service"`
+	Environment    string              `json:"environment,omitempty"`
+	TotalChecks    int                 `json:"total_checks"`
+	Uptime         int                 `json:"uptime_count"`
+	Downtime       int                 `json:"downtime_count"`
+	Degraded       int                 `json:"degraded_count"`
+	Blocked        int                 `json:"blocked_count"`
+	Maintenance    int                 `json:"maintenance_count"`
+	UptimePercent  float64             `json:"uptime_percent"`
+	AverageLatency float64             `json:"average_latency_ms"`
+	HealthScore    float64             `json:"health_score"` // 0-100, see healthScore
+	FlakyDomains   []string            `json:"flaky_domains,omitempty"`
+	RunDuration    int64               `json:"run_duration_ms"`
+	Unreached      []string            `json:"unreached_domains,omitempty"`  // not started before the run deadline
+	Unfinished     []string            `json:"unfinished_domains,omitempty"` // still running when the run deadline passed
+	SRVTargets     map[string][]string `json:"srv_targets,omitempty"`        // targets resolved for each SRV entry
+	SubmittedTo    []string            `json:"submitted_to,omitempty"`
+	ReportFile     string              `json:"report_file,omitempty"`   // where SaveReport wrote the report
+	ChangesSince   *time.Time          `json:"changes_since,omitempty"` // timestamp of the run Changes compares against
+	Changes        []DomainDiff        `json:"changes,omitempty"`
+	Timestamp      time.Time           `json:"timestamp"`
+	Results        []HealthCheckResult `json:"results"`
+}
+
+// MonitorConfig holds every setting; see NewMonitorConfig
+type MonitorConfig struct {
+	Domains                []string
+	DroppedDomains         []string // empty or duplicate domain entries that were skipped
+	APIURL                 string
+	APIKey                 string
+	Timeout                time.Duration
+	UserAgent              string // Monitor User-Agent
+	Concurrent             int
+	Environment            string
+	OutputDir              string
+	SlackWebhook           string
+	DiscordWebhook         string
+	TelegramBotToken       string
+	TelegramChatID         string
+	PagerDutyRoutingKey    string                 // Events API v2 integration key
+	WebhookNotifier        *WebhookNotifierConfig // WEBHOOK_*, nil when WEBHOOK_URL is unset
+	EmailAuth              string
+	EmailTo                []string
+	EmailCC                []string // EMAIL_CC, added as a Cc header
+	EmailBCC               []string // EMAIL_BCC, delivered to but never listed in headers
+	EmailUser              string
+	EmailGroups            []EmailGroup     // EMAIL_GROUPS, sent alongside EmailTo
+	SMTPHost               string           // smtp.gmail.com
+	SMTPPort               string           // 587
+	SMTPTLSMode            string           // auto, implicit or starttls
+	SMTPAuthMethod         string           // plain or xoauth2
+	SMTPOAuth              *SMTPOAuthConfig // SMTP_OAUTH_*, set for xoauth2
+	MaxRetries             int              // RETRY_MAX, the default for every retry curve
+	RateLimiter            *rate.Limiter
+	RetryRateLimiter       *rate.Limiter       // RETRY_RATE_LIMIT, nil exempts retries
+	MaintenanceWindows     []MaintenanceWindow // MAINTENANCE_WINDOWS, global and per-domain-list windows
+	Interval               time.Duration       // daemon mode when > 0
+	HistorySize            int
+	HistoryFile            string
+	DefaultScheme          string // scheme used for domains without one
+	SourceAddr             net.IP // local address outgoing connections are bound to
+	DomainConfigs          map[string]DomainConfig
+	APITargets             []APITarget   // API_URL first, followed by API_TARGETS
+	APIGzipThreshold       int           // API_GZIP_THRESHOLD bytes; -1 sends submissions uncompressed
+	APIIdempotencyHeader   string        // API_IDEMPOTENCY_HEADER, empty when disabled
+	APISigningSecret       string        // API_SIGNING_SECRET, never logged
+	OutputCompression      string        // none or gzip
+	ReportFormats          []string      // json, csv and/or markdown
+	ReportFilenameTemplate string        // path under OutputDir, see renderReportFilename
+	ReportRetentionDays    int           // delete JSON reports older than this many days, 0 keeps them
+	ReportMaxFiles         int           // keep at most this many JSON reports, 0 for no limit
+	MetricsFile            string        // Prometheus textfile written after each run
+	MetricsAddr            string        // address /metrics is served on in daemon mode
+	OutputIndent           bool          // pretty-print saved reports
+	HTMLMaxRows            int           // cap on detailed rows in HTML reports (0 = all)
+	ChartInline            bool          // embed the HTML report chart instead of uploading it
+	Chart                  ChartOptions  // CHART_WIDTH, CHART_HEIGHT, CHART_BAR_WIDTH, CHART_COLORS
+	ChartStorage           string        // supabase, s3 or local
+	S3                     *S3Config     // S3_*, set when ChartStorage is s3
+	ChartURLExpiry         time.Duration // CHART_URL_EXPIRY, 0 links the public URL
+	SupabaseBucket         string        // SUPABASE_BUCKET for uploaded charts
+	SupabaseBucketPrivate  bool          // SUPABASE_BUCKET_PRIVATE, charts are linked with signed URLs
+	ChartUploadRetry       RetryConfig   // chart uploads (CHART_UPLOAD_RETRY_*)
+	ChartUploadTimeout     time.Duration // bounds a chart upload, retries included
+	BreakerThreshold       int           // consecutive failed runs before a circuit opens (0 disables)
+	BreakerProbeInterval   time.Duration
+	NotificationsDisabled  bool          // suppress every notification channel, including email
+	NotifyOnChange         bool          // only alert on domains whose status changed since the previous run
+	EmailAlerts            bool          // also email failure alerts to the recipient groups
+	NotifyCooldown         time.Duration // minimum gap between alerts for the same domain (0 disables)
+	NotifyStateFile        string        // last-alerted timestamps used by NotifyCooldown
+	PostRunCommand         string        // shell command run after each cycle with the report on stdin
+	CheckRetry             RetryConfig   // domain checks (CHECK_RETRY_*)
+	SubmitRetry            RetryConfig   // API submissions (SUBMIT_RETRY_*)
+	WebhookRetry           RetryConfig   // notification webhooks (WEBHOOK_RETRY_*)
+	WebhookSigningSecret   string        // WEBHOOK_SIGNING_SECRET, never logged
+	ScoreWeights           ScoreWeights  // HEALTH_SCORE_WEIGHTS
+	Queue                  QueueConfig   // message queue sink, disabled when Queue.URL is empty
+	LockFile               string        // PID lockfile guarding against overlapping runs
+	MinRunInterval         time.Duration // refuse to run sooner than this after the previous run
+	ResolveDNS             bool          // resolve each domain before checking it and record the addresses
+	MinTLSVersion          uint16        // HTTPS checks negotiating an older version are degraded (0 disables)
+	SamplesPerCheck        int           // timed requests per HTTP check; status uses their median
+	ExitPolicy             string        // down, degraded or uptime: what makes a run exit with status 1
+	MinUptimePercent       float64       // uptime below this breaches the uptime exit policy
+
+	problems []string // values NewMonitorConfig could not parse, reported by Validate
+	warnings []string // values NewMonitorConfig replaced with a default, logged by NewUptimeMonitor
+}
+
+// APITarget is a backend the report is submitted to
+type APITarget struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`               // may use {{.Environment}}, {{.Service}} and {{.Timestamp}}
+	Method  string            `json:"method,omitempty"`  // POST (default), PUT or PATCH
+	APIKey  string            `json:"api_key,omitempty"` // sent as a Bearer token
+	Headers map[string]string `json:"headers,omitempty"`
+	// Top-level keys the JSON response must contain; validation is skipped when empty
+	ExpectKeys []string `json:"expect_keys,omitempty"`
+}
+
+// UptimeMonitor runs checks and delivers their reports. It is safe to call
+// RunCheck again once the previous call has returned.
+type UptimeMonitor struct {
+	config    *MonitorConfig
+	logger    *zap.Logger
+	client    *http.Client
+	transport *http.Transport
+	history   *HistoryCache
+	breaker   *CircuitBreaker
+
+	clientsMu sync.Mutex
+	clients   map[string]*http.Client // per-domain clients with their own TLS settings
+
+	notifiers    []Notifier
+	notifyState  *notifyState     // loaded by SendNotifications when NotifyCooldown is set
+	smtpTokens   *smtpTokenSource // XOAUTH2 tokens, nil for plain auth
+	chartStorage ChartStorage     // CHART_STORAGE backend for uploaded charts
+	metrics      metricsStore     // latest Prometheus rendering for MetricsAddr
+
+	srvMu     sync.RWMutex
+	srvGroups map[string]string // SRV target -> entry it was resolved from, for the current run
+}
+
+type RetryConfig struct {
+	MaxRetries        int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// DefaultRetryConfig returns a default RetryConfig
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:        MaxRetries,
+		InitialBackoff:    InitialBackoff,
+		MaxBackoff:        MaxBackoff,
+		BackoffMultiplier: BackoffMultiplier,
+	}
+}
+
+// parseRetryConfig reads <prefix>_MAX_RETRIES, <prefix>_INITIAL_BACKOFF,
+// <prefix>_MAX_BACKOFF and <prefix>_BACKOFF_MULTIPLIER over the given defaults
+func parseRetryConfig(prefix string, defaults RetryConfig) (RetryConfig, error) {
+	rc := defaults
+
+	if v := getEnv(prefix + "_MAX_RETRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return rc, fmt.Errorf("%s_MAX_RETRIES must be a non-negative integer, got %q", prefix, v)
+		}
+		rc.MaxRetries = n
+	}
+
+	for _, d := range []struct {
+		key string
+		dst *time.Duration
+	}{
+		{prefix + "_INITIAL_BACKOFF", &rc.InitialBackoff},
+		{prefix + "_MAX_BACKOFF", &rc.MaxBackoff},
+	} {
+		if v := getEnv(d.key); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return rc, fmt.Errorf("%s must be a positive duration, got %q", d.key, v)
+			}
+			*d.dst = parsed
+		}
+	}
+
+	if v := getEnv(prefix + "_BACKOFF_MULTIPLIER"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 1 {
+			return rc, fmt.Errorf("%s_BACKOFF_MULTIPLIER must be a number >= 1, got %q", prefix, v)
+		}
+		rc.BackoffMultiplier = f
+	}
+
+	return rc, nil
+}
+
+// parseBaseRetryConfig reads the RETRY_* defaults shared by every retry
+// curve. RETRY_MAX is accepted as the shorter name for RETRY_MAX_RETRIES.
+func parseBaseRetryConfig() (RetryConfig, error) {
+	rc := DefaultRetryConfig()
+	if v := getEnv("RETRY_MAX"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return rc, fmt.Errorf("RETRY_MAX must be a non-negative integer, got %q", v)
+		}
+		rc.MaxRetries = n
+	}
+	return parseRetryConfig("RETRY", rc)
+}
+
+// CalculateBackoff calculates exponential backoff duration
+func (rc RetryConfig) CalculateBackoff(attempt int) time.Duration {
+	backoff := float64(rc.InitialBackoff) * math.Pow(rc.BackoffMultiplier, float64(attempt))
+	if backoff > float64(rc.MaxBackoff) {
+		return rc.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// retryAfter returns the delay requested by a 429 or 503 response's
+// Retry-After header, in either its seconds or HTTP-date form, capped at
+// limit, or 0
+func retryAfter(resp *http.Response, limit time.Duration) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return min(wait, limit)
+}
+
+// retryAfterLimit is the longest Retry-After a retry honours: the curve's
+// MaxBackoff, or the time left before ctx's deadline when that is sooner, so
+// a server asking for hours can't hold the run until it is cancelled
+func retryAfterLimit(ctx context.Context, rc RetryConfig) time.Duration {
+	limit := rc.MaxBackoff
+	if deadline, ok := ctx.Deadline(); ok {
+		limit = min(limit, time.Until(deadline))
+	}
+	return limit
+}
+
+// IsRetryableError determines if an error should be retried. Network errors
+// are retried unless they can't succeed on a repeat (cancellation, TLS
+// failures); errors from building the request are not. An error that came
+// with a response, such as a failed API submission, is judged by statusCode.
+func IsRetryableError(err error, statusCode int) bool {
+	if err != nil {
+		switch classifyFailure(err) {
+		case FailureCancelled, FailureTLS:
+			return false
+		case FailureTimeout, FailureConnectionRefused, FailureConnectionReset, FailureDNS:
+			return true
+		}
+
+		// *url.Error satisfies net.Error itself, so look at what it wraps
+		// to tell a network failure from e.g. an unsupported scheme
+		cause := err
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			cause = urlErr.Err
+		}
+		var netErr net.Error
+		if errors.As(cause, &netErr) {
+			return true
+		}
+		if statusCode == 0 {
+			return false
+		}
+	}
+
+	// Retry on specific status codes
+	switch statusCode {
+	case http.StatusTooManyRequests, // 429
+		http.StatusInternalServerError, // 500
+		http.StatusBadGateway,          // 502
+		http.StatusServiceUnavailable,  // 503
+		http.StatusGatewayTimeout:      // 504
+		return true
+	default:
+		return false
+	}
+}
+
+// NewMonitorConfig builds the configuration from environment variables and
+// the optional CONFIG_FILE. Only an unreadable CONFIG_FILE is returned as an
+// error; every value it cannot parse is reported by Validate.
+func NewMonitorConfig() (*MonitorConfig, error) {
+	fileConfig, err := loadConfigFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	useFileSettings(fileConfig)
+
+	// Problems are collected rather than returned one at a time, so Validate
+	// can report every bad setting in one pass
+	var problems []string
+	var warnings []string
+
+	var domainEntries []string
+	if domainsStr := getEnv("MONITOR_DOMAINS"); domainsStr != "" {
+		domainEntries = strings.Split(domainsStr, ",")
+	} else if fileConfig != nil {
+		domainEntries = fileConfig.DomainList()
+	}
+	// MONITOR_DOMAINS_FILE adds to the list rather than replacing it
+	if path := getEnv("MONITOR_DOMAINS_FILE"); path != "" {
+		fileDomains, err := loadDomainsFile(path)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		domainEntries = append(domainEntries, fileDomains...)
+	}
+	apiUrl := getEnv("API_URL")
+	apiTargetsStr := getEnv("API_TARGETS")
+
+	apiTargets, err := parseAPITargets(apiUrl, getEnv("API_KEY"), getEnv("API_EXPECT_KEYS"), getEnv("API_METHOD"), apiTargetsStr)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	domains, droppedDomains := normalizeDomains(domainEntries)
+
+	emailTo := splitAddresses(getEnv("EMAIL_TO"))
+	emailCC := splitAddresses(getEnv("EMAIL_CC"))
+	emailBCC := splitAddresses(getEnv("EMAIL_BCC"))
+
+	timeout := DefaultTimeout
+	if timeoutStr := getEnv("MONITOR_TIMEOUT"); timeoutStr != "" {
+		if d, err := time.ParseDuration(timeoutStr); err == nil {
+			timeout = d
+		} else {
+			problems = append(problems, fmt.Sprintf("MONITOR_TIMEOUT %q is not a duration (e.g. 30s)", timeoutStr))
+		}
+	}
+
+	concurrent := DefaultConcurrent
+	if concurrentStr := getEnv("MONITOR_CONCURRENT"); concurrentStr != "" {
+		// A semaphore of size 0 would block every check, so anything below
+		// 1 falls back to the default rather than hanging the run
+		if n, err := strconv.Atoi(strings.TrimSpace(concurrentStr)); err == nil && n >= 1 {
+			concurrent = n
+		} else {
+			warnings = append(warnings, fmt.Sprintf("MONITOR_CONCURRENT %q is not a positive whole number, using %d", concurrentStr, DefaultConcurrent))
+		}
+	}
+
+	var interval time.Duration
+	if intervalStr := getEnv("MONITOR_INTERVAL"); intervalStr != "" {
+		if d, err := time.ParseDuration(intervalStr); err == nil {
+			interval = d
+		} else {
+			problems = append(problems, fmt.Sprintf("MONITOR_INTERVAL %q is not a duration (e.g. 5m)", intervalStr))
+		}
+	}
+
+	historySize := DefaultHistorySize
+	if historyStr := getEnv("HISTORY_SIZE"); historyStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(historyStr)); err == nil {
+			historySize = n
+		} else {
+			problems = append(problems, fmt.Sprintf("HISTORY_SIZE %q is not a whole number", historyStr))
+		}
+	}
+
+	reportFormats, err := parseReportFormats(getEnvOrDefault("REPORT_FORMAT", ReportFormatJSON))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	retentionDays := 0
+	if daysStr := getEnv("REPORT_RETENTION_DAYS"); daysStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(daysStr)); err == nil {
+			retentionDays = n
+		} else {
+			problems = append(problems, fmt.Sprintf("REPORT_RETENTION_DAYS %q is not a whole number", daysStr))
+		}
+	}
+
+	maxFiles := 0
+	if maxStr := getEnv("REPORT_MAX_FILES"); maxStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(maxStr)); err == nil {
+			maxFiles = n
+		} else {
+			problems = append(problems, fmt.Sprintf("REPORT_MAX_FILES %q is not a whole number", maxStr))
+		}
+	}
+
+	apiGzipThreshold := -1
+	if thresholdStr := getEnv("API_GZIP_THRESHOLD"); thresholdStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(thresholdStr)); err == nil && n >= 0 {
+			apiGzipThreshold = n
+		} else {
+			problems = append(problems, fmt.Sprintf("API_GZIP_THRESHOLD %q is not a non-negative whole number", thresholdStr))
+		}
+	}
+
+	apiIdempotencyHeader := strings.TrimSpace(getEnvOrDefault("API_IDEMPOTENCY_HEADER", DefaultIdempotencyHeader))
+	if strings.EqualFold(apiIdempotencyHeader, "none") {
+		apiIdempotencyHeader = ""
+	} else if !validHeaderName(apiIdempotencyHeader) {
+		problems = append(problems, fmt.Sprintf("API_IDEMPOTENCY_HEADER %q is not a valid header name", apiIdempotencyHeader))
+	}
+
+	reportFilenameTemplate := getEnvOrDefault("REPORT_FILENAME_TEMPLATE", DefaultReportFilenameTemplate)
+	if err := validateReportFilenameTemplate(reportFilenameTemplate, getEnvOrDefault("ENVIRONMENT", "production")); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	compression := strings.ToLower(getEnvOrDefault("OUTPUT_COMPRESSION", CompressionNone))
+	if compression != CompressionNone && compression != CompressionGzip {
+		problems = append(problems, fmt.Sprintf("OUTPUT_COMPRESSION must be none or gzip, got %q", compression))
+	}
+
+	htmlMaxRows := DefaultHTMLMaxRows
+	if rowsStr := getEnv("HTML_MAX_ROWS"); rowsStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(rowsStr)); err == nil && n >= 0 {
+			htmlMaxRows = n
+		} else {
+			problems = append(problems, fmt.Sprintf("HTML_MAX_ROWS %q is not a non-negative whole number", rowsStr))
+		}
+	}
+
+	breakerThreshold := 0
+	if thresholdStr := getEnv("BREAKER_THRESHOLD"); thresholdStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(thresholdStr)); err == nil && n >= 0 {
+			breakerThreshold = n
+		} else {
+			problems = append(problems, fmt.Sprintf("BREAKER_THRESHOLD %q is not a non-negative whole number", thresholdStr))
+		}
+	}
+
+	breakerProbe := DefaultBreakerProbeInterval
+	if probeStr := getEnv("BREAKER_PROBE_INTERVAL"); probeStr != "" {
+		if d, err := time.ParseDuration(probeStr); err == nil && d > 0 {
+			breakerProbe = d
+		} else {
+			problems = append(problems, fmt.Sprintf("BREAKER_PROBE_INTERVAL %q is not a positive duration", probeStr))
+		}
+	}
+
+	emailGroups, err := parseEmailGroups(getEnv("EMAIL_GROUPS"))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	var notifyCooldown time.Duration
+	if cooldownStr := getEnv("NOTIFY_COOLDOWN"); cooldownStr != "" {
+		if d, err := time.ParseDuration(cooldownStr); err == nil {
+			notifyCooldown = d
+		} else {
+			problems = append(problems, fmt.Sprintf("NOTIFY_COOLDOWN %q is not a duration (e.g. 15m)", cooldownStr))
+		}
+	}
+
+	smtpMode, err := parseSMTPTLSMode(getEnv("SMTP_TLS_MODE"))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	smtpAuthMethod, smtpOAuth, err := parseSMTPOAuthConfig()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	webhookConfig, err := parseWebhookNotifierConfig()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	outputDir := getEnvOrDefault("OUTPUT_DIR", "./reports")
+
+	scheme := strings.ToLower(getEnvOrDefault("DEFAULT_SCHEME", DefaultScheme))
+	if scheme != "http" && scheme != "https" {
+		problems = append(problems, fmt.Sprintf("DEFAULT_SCHEME must be http or https, got %q", scheme))
+	}
+
+	sourceAddr, err := resolveSourceAddress(getEnv("SOURCE_ADDRESS"))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	domainConfigs, err := parseDomainConfigs(getEnv("MONITOR_DOMAIN_CONFIG"))
+	if err != nil {
+		problems = append(problems, err.Error())
+		domainConfigs = make(map[string]DomainConfig)
+	}
+	if fileConfig != nil {
+		// Entries from MONITOR_DOMAIN_CONFIG win over the file's
+		// Keyed like DomainList, so a URL padded with spaces keeps its overrides
+		for _, domain := range fileConfig.Domains {
+			domainURL := strings.TrimSpace(domain.URL)
+			if _, ok := domainConfigs[domainURL]; !ok {
+				domainConfigs[domainURL] = domain.DomainConfig
+			}
+		}
+	}
+
+	if _, err := dependencyLevels(domains, domainConfigs); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid MONITOR_DOMAIN_CONFIG: %v", err))
+	}
+
+	// SRV entries are replaced by their targets at run time, so nothing can
+	// depend on one by name
+	for domain, dc := range domainConfigs {
+		for _, parent := range dc.DependsOn {
+			if isSRVDomain(parent) {
+				problems = append(problems, fmt.Sprintf("invalid MONITOR_DOMAIN_CONFIG for %s: cannot depend on SRV entry %s", domain, parent))
+			}
+		}
+	}
+
+	// RETRY_* sets the curve every operation starts from; the per-operation
+	// prefixes below override it
+	baseRetry, err := parseBaseRetryConfig()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	checkRetry, err := parseRetryConfig("CHECK_RETRY", baseRetry)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	submitRetry, err := parseRetryConfig("SUBMIT_RETRY", baseRetry)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	// Webhooks were historically sent once, so they don't retry unless asked to
+	webhookDefaults := baseRetry
+	webhookDefaults.MaxRetries = 0
+	webhookRetry, err := parseRetryConfig("WEBHOOK_RETRY", webhookDefaults)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	chartOptions, err := parseChartOptions()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	chartUploadRetry, err := parseRetryConfig("CHART_UPLOAD_RETRY", baseRetry)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	chartUploadTimeout := DefaultChartUploadTimeout
+	if timeoutStr := getEnv("CHART_UPLOAD_TIMEOUT"); timeoutStr != "" {
+		if d, err := time.ParseDuration(timeoutStr); err == nil && d > 0 {
+			chartUploadTimeout = d
+		} else {
+			problems = append(problems, fmt.Sprintf("CHART_UPLOAD_TIMEOUT %q is not a positive duration", timeoutStr))
+		}
+	}
+
+	var chartURLExpiry time.Duration
+	if expiryStr := getEnv("CHART_URL_EXPIRY"); expiryStr != "" {
+		if d, err := time.ParseDuration(expiryStr); err == nil && d > 0 {
+			chartURLExpiry = d
+		} else {
+			problems = append(problems, fmt.Sprintf("CHART_URL_EXPIRY %q is not a positive duration", expiryStr))
+		}
+	}
+
+	chartStorage := strings.ToLower(getEnvOrDefault("CHART_STORAGE", defaultChartStorage()))
+	var s3Config *S3Config
+	switch chartStorage {
+	case ChartStorageSupabase, ChartStorageLocal:
+	case ChartStorageS3:
+		if s3Config, err = parseS3Config(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("CHART_STORAGE must be supabase, s3 or local, got %q", chartStorage))
+	}
+
+	scoreWeights, err := parseScoreWeights(getEnv("HEALTH_SCORE_WEIGHTS"))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	queue, err := parseQueueConfig()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	var minRunInterval time.Duration
+	if minStr := getEnv("MIN_RUN_INTERVAL"); minStr != "" {
+		if d, err := time.ParseDuration(minStr); err == nil && d >= 0 {
+			minRunInterval = d
+		} else {
+			problems = append(problems, fmt.Sprintf("MIN_RUN_INTERVAL %q is not a duration (e.g. 1m)", minStr))
+		}
+	}
+
+	var minTLSVersion uint16
+	if v := getEnv("MIN_TLS_VERSION"); v != "" {
+		if minTLSVersion, err = parseTLSVersion(v); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid MIN_TLS_VERSION: %v", err))
+		}
+	}
+
+	samplesPerCheck := 1
+	if samplesStr := getEnv("SAMPLES_PER_CHECK"); samplesStr != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(samplesStr)); err == nil && n >= 1 {
+			samplesPerCheck = n
+		} else {
+			problems = append(problems, fmt.Sprintf("SAMPLES_PER_CHECK %q is not a positive whole number", samplesStr))
+		}
+	}
+
+	// Setting MIN_UPTIME_PERCENT alone is enough to select the uptime policy
+	var minUptimePercent float64
+	exitPolicy := ExitPolicyDown
+	if minStr := getEnv("MIN_UPTIME_PERCENT"); minStr != "" {
+		exitPolicy = ExitPolicyUptime
+		if f, err := strconv.ParseFloat(strings.TrimSpace(minStr), 64); err == nil && f >= 0 && f <= 100 {
+			minUptimePercent = f
+		} else {
+			problems = append(problems, fmt.Sprintf("MIN_UPTIME_PERCENT %q is not a number between 0 and 100", minStr))
+		}
+	}
+	if policyStr := getEnv("EXIT_POLICY"); policyStr != "" {
+		exitPolicy = strings.ToLower(strings.TrimSpace(policyStr))
+		if exitPolicy == ExitPolicyUptime && getEnv("MIN_UPTIME_PERCENT") == "" {
+			problems = append(problems, "EXIT_POLICY=uptime needs MIN_UPTIME_PERCENT")
+		}
+	}
+
+	maintenanceWindows, err := parseMaintenanceWindows(getEnv("MAINTENANCE_WINDOWS"))
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	rateLimiter := rate.NewLimiter(rate.Limit(RequestsPerSecond), BurstSize)
+	retryRateLimiter, err := newRetryRateLimiter(getEnvOrDefault("RETRY_RATE_LIMIT", RetryRateLimitShared), rateLimiter)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	return &MonitorConfig{
+		Domains:                domains,
+		DroppedDomains:         droppedDomains,
+		APIURL:                 getEnvOrDefault("API_URL", ""),
+		APIKey:                 getEnv("API_KEY"),
+		Timeout:                timeout,
+		UserAgent:              getEnvOrDefault("USER_AGENT", DefaultUserAgent),
+		Concurrent:             concurrent,
+		Environment:            getEnvOrDefault("ENVIRONMENT", "production"),
+		OutputDir:              outputDir,
+		SlackWebhook:           getEnv("SLACK_WEBHOOK_URL"),
+		DiscordWebhook:         getEnv("DISCORD_WEBHOOK_URL"),
+		TelegramBotToken:       getEnv("TELEGRAM_BOT_TOKEN"),
+		TelegramChatID:         getEnv("TELEGRAM_CHAT_ID"),
+		PagerDutyRoutingKey:    getEnv("PAGERDUTY_ROUTING_KEY"),
+		WebhookNotifier:        webhookConfig,
+		EmailAuth:              getEnv("EMAIL_AUTH"),
+		EmailTo:                emailTo,
+		EmailCC:                emailCC,
+		EmailBCC:               emailBCC,
+		EmailUser:              getEnv("EMAIL_USER"),
+		EmailGroups:            emailGroups,
+		SMTPHost:               getEnvOrDefault("SMTP_HOST", DefaultSMTPHost),
+		SMTPPort:               getEnvOrDefault("SMTP_PORT", DefaultSMTPPort),
+		SMTPTLSMode:            smtpMode,
+		SMTPAuthMethod:         smtpAuthMethod,
+		SMTPOAuth:              smtpOAuth,
+		MaxRetries:             baseRetry.MaxRetries,
+		RateLimiter:            rateLimiter,
+		RetryRateLimiter:       retryRateLimiter,
+		MaintenanceWindows:     maintenanceWindows,
+		Interval:               interval,
+		HistorySize:            historySize,
+		HistoryFile:            getEnvOrDefault("HISTORY_FILE", filepath.Join(outputDir, "history.json")),
+		DefaultScheme:          scheme,
+		SourceAddr:             sourceAddr,
+		DomainConfigs:          domainConfigs,
+		APITargets:             apiTargets,
+		APIGzipThreshold:       apiGzipThreshold,
+		APIIdempotencyHeader:   apiIdempotencyHeader,
+		APISigningSecret:       getEnv("API_SIGNING_SECRET"),
+		OutputCompression:      compression,
+		ReportFormats:          reportFormats,
+		ReportFilenameTemplate: reportFilenameTemplate,
+		ReportRetentionDays:    retentionDays,
+		ReportMaxFiles:         maxFiles,
+		MetricsFile:            getEnv("METRICS_FILE"),
+		MetricsAddr:            getEnv("METRICS_ADDR"),
+		OutputIndent:           getEnvBool("OUTPUT_INDENT", true),
+		HTMLMaxRows:            htmlMaxRows,
+		ChartInline:            getEnvBool("CHART_INLINE", false),
+		Chart:                  chartOptions,
+		ChartStorage:           chartStorage,
+		S3:                     s3Config,
+		ChartURLExpiry:         chartURLExpiry,
+		SupabaseBucket:         getEnvOrDefault("SUPABASE_BUCKET", DefaultChartBucket),
+		SupabaseBucketPrivate:  getEnvBool("SUPABASE_BUCKET_PRIVATE", false),
+		ChartUploadRetry:       chartUploadRetry,
+		ChartUploadTimeout:     chartUploadTimeout,
+		BreakerThreshold:       breakerThreshold,
+		BreakerProbeInterval:   breakerProbe,
+		NotificationsDisabled:  getEnvBool("NOTIFICATIONS_DISABLED", false),
+		NotifyOnChange:         getEnvBool("NOTIFY_ON_CHANGE", false),
+		EmailAlerts:            getEnvBool("EMAIL_ALERTS", false),
+		NotifyCooldown:         notifyCooldown,
+		NotifyStateFile:        getEnvOrDefault("NOTIFY_STATE_FILE", filepath.Join(outputDir, "notify_state.json")),
+		PostRunCommand:         getEnv("POST_RUN_COMMAND"),
+		CheckRetry:             checkRetry,
+		SubmitRetry:            submitRetry,
+		WebhookRetry:           webhookRetry,
+		WebhookSigningSecret:   getEnv("WEBHOOK_SIGNING_SECRET"),
+		ScoreWeights:           scoreWeights,
+		Queue:                  queue,
+		LockFile:               getEnvOrDefault("LOCK_FILE", filepath.Join(outputDir, "monitor.lock")),
+		MinRunInterval:         minRunInterval,
+		ResolveDNS:             getEnvBool("RESOLVE_DNS", false),
+		MinTLSVersion:          minTLSVersion,
+		SamplesPerCheck:        samplesPerCheck,
+		ExitPolicy:             exitPolicy,
+		MinUptimePercent:       minUptimePercent,
+		problems:               problems,
+		warnings:               warnings,
+	}, nil
+}
+
+// normalizeDomains trims the entries, drops empty ones and removes duplicates
+// while keeping the first occurrence in order. Bare hostnames are compared
+// case-insensitively; URLs, tcp:// and srv: entries must match exactly. The
+// dropped entries are returned so they can be reported.
+func normalizeDomains(entries []string) (domains, dropped []string) {
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		domain := strings.TrimSpace(entry)
+		if domain == "" {
+			dropped = append(dropped, entry)
+			continue
+		}
+
+		key := domain
+		if !strings.Contains(domain, "://") && !strings.Contains(domain, "/") && !strings.HasPrefix(domain, SRVPrefix) {
+			key = strings.ToLower(domain)
+		}
+		if seen[key] {
+			dropped = append(dropped, domain)
+			continue
+		}
+		seen[key] = true
+		domains = append(domains, domain)
+	}
+	return domains, dropped
+}
+
+// parseAPITargets builds the submission targets from API_URL/API_KEY/API_EXPECT_KEYS
+// and the API_TARGETS JSON array
+func parseAPITargets(apiURL, apiKey, expectKeys, method, raw string) ([]APITarget, error) {
+	var targets []APITarget
+	if apiURL != "" {
+		target := APITarget{Name: "default", URL: apiURL, Method: method, APIKey: apiKey}
+		for _, key := range strings.Split(expectKeys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				target.ExpectKeys = append(target.ExpectKeys, key)
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	if raw != "" {
+		var extra []APITarget
+		if err := json.Unmarshal([]byte(raw), &extra); err != nil {
+			return nil, fmt.Errorf("invalid API_TARGETS: %w", err)
+		}
+
+		for i, target := range extra {
+			if target.URL == "" {
+				return nil, fmt.Errorf("invalid API_TARGETS: entry %d has no url", i)
+			}
+			if target.Name == "" {
+				extra[i].Name = target.URL
+			}
+		}
+		targets = append(targets, extra...)
+	}
+
+	for i := range targets {
+		targets[i].Method = strings.ToUpper(strings.TrimSpace(targets[i].Method))
+		if targets[i].Method == "" {
+			targets[i].Method = http.MethodPost
+		}
+		if err := validateAPITarget(targets[i]); err != nil {
+			return nil, fmt.Errorf("invalid API target %s: %w", targets[i].Name, err)
+		}
+	}
+
+	return targets, nil
+}
+
+// resolveSourceAddress parses SOURCE_ADDRESS, which may be an IP address or the
+// name of a network interface whose first address should be used
+func resolveSourceAddress(value string) (net.IP, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if ip := net.ParseIP(value); ip != nil {
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(value)
+	if err != nil {
+		return nil, fmt.Errorf("SOURCE_ADDRESS %q is neither an IP address nor an interface: %w", value, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addresses of interface %s: %w", value, err)
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			return ipNet.IP, nil
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s has no usable address", value)
+}
+
+// NewUptimeMonitor creates a monitor for a validated config. Pass zap.NewNop()
+// to discard its logs.
+func NewUptimeMonitor(config *MonitorConfig, logger *zap.Logger) *UptimeMonitor {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if config.SourceAddr != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: config.SourceAddr}
+	}
+
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: false},
+	}
+
+	client := &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}
+
+	breaker := NewCircuitBreaker(config.BreakerThreshold, config.BreakerProbeInterval)
+	history := NewHistoryCache(config.HistorySize, config.HistoryFile)
+	history.breaker = breaker
+
+	monitor := &UptimeMonitor{
+		config:    config,
+		logger:    logger,
+		client:    client,
+		transport: transport,
+		history:   history,
+		breaker:   breaker,
+		clients:   make(map[string]*http.Client),
+	}
+	monitor.chartStorage = newChartStorage(config, client)
+	if config.SMTPOAuth != nil {
+		monitor.smtpTokens = newSMTPTokenSource(config.SMTPOAuth, client)
+	}
+	monitor.registerConfiguredNotifiers()
+
+	for _, warning := range config.warnings {
+		logger.Warn("Configuration value adjusted", zap.String("detail", warning))
+	}
+
+	return monitor
+}
+
+// clientFor returns the HTTP client for a domain, building (and caching) a
+// dedicated transport when the domain needs its own TLS settings
+func (m *UptimeMonitor) clientFor(settings DomainConfig) *http.Client {
+	if settings.ServerName == "" && !settings.InsecureSkipVerify && settings.Timeout == 0 {
+		return m.client
+	}
+
+	key := fmt.Sprintf("sni=%s insecure=%t timeout=%d", settings.ServerName, settings.InsecureSkipVerify, settings.Timeout)
+
+	m.clientsMu.Lock()
+	defer m.clientsMu.Unlock()
+
+	if client, ok := m.clients[key]; ok {
+		return client
+	}
+
+	transport := m.transport.Clone()
+	transport.TLSClientConfig.ServerName = settings.ServerName
+	transport.TLSClientConfig.InsecureSkipVerify = settings.InsecureSkipVerify
+
+	client := &http.Client{
+		Timeout:       settings.RequestTimeout(m.client.Timeout),
+		Transport:     transport,
+		CheckRedirect: m.client.CheckRedirect,
+	}
+	m.clients[key] = client
+	return client
+}
+
+// CheckDomain checks a single domain with retries and logs one line for the
+// outcome
+func (m *UptimeMonitor) CheckDomain(ctx context.Context, domain string) HealthCheckResult {
+	result := m.checkDomain(ctx, domain)
+	m.logCheck(result)
+	return result
+}
+
+// logCheck logs a completed check with its key fields, named as in the JSON
+// report, so per-domain history can be rebuilt from the logs alone. Healthy
+// checks log at debug and failures at info, keeping LOG_LEVEL=info quiet
+// while everything is up.
+func (m *UptimeMonitor) logCheck(result HealthCheckResult) {
+	level := zapcore.DebugLevel
+	if result.Status == StatusDown || result.Status == StatusDegraded {
+		level = zapcore.InfoLevel
+	}
+	ce := m.logger.Check(level, "Health check completed")
+	if ce == nil {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("domain", result.Domain),
+		zap.String("status", result.Status),
+		zap.Int("status_code", result.StatusCode),
+		zap.Int64("response_time_ms", result.ResponseTime),
+		zap.Int("attempts", result.Attempts),
+	}
+	if result.IsSSL && result.SSLExpiry != "" {
+		fields = append(fields, zap.Int("ssl_days_left", result.SSLDaysLeft))
+	}
+	if result.FailureReason != "" {
+		fields = append(fields, zap.String("failure_reason", result.FailureReason))
+	}
+	ce.Write(fields...)
+}
+
+func (m *UptimeMonitor) checkDomain(ctx context.Context, domain string) HealthCheckResult {
+	retryConfig := m.config.CheckRetry
+	settings := m.domainSettings(domain)
+
+	if isTCPDomain(domain) {
+		return m.checkTCP(ctx, domain, settings)
+	}
+
+	if len(settings.Steps) > 0 {
+		return m.checkTransaction(ctx, domain, settings)
+	}
+
+	var resolvedIPs []string
+	var resolveTime int64
+	if m.config.ResolveDNS {
+		ips, elapsed, err := m.resolveHost(ctx, m.checkURL(domain))
+		if err != nil {
+			return HealthCheckResult{
+				Domain:         domain,
+				URL:            redactURL(m.checkURL(domain)),
+				Status:         StatusDown,
+				ErrorMessage:   fmt.Sprintf("DNS resolution failed: %v", err),
+				FailureReason:  FailureDNS,
+				DNSResolveTime: elapsed,
+				Attempts:       1,
+				Timestamp:      time.Now(),
+				CheckedAt:      time.Now().UTC().Format(time.RFC3339),
+			}
+		}
+		resolvedIPs, resolveTime = ips, elapsed
+	}
+
+	warmedUp := settings.WarmUp && m.warmUp(ctx, domain, settings)
+
+	var lastResult HealthCheckResult
+	var retryDelay time.Duration
+
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
+
+		if err := m.waitRateLimit(ctx, attempt); err != nil {
+
+			return HealthCheckResult{
+				Domain:          domain,
+				URL:             redactURL(domain),
+				Status:          StatusDown,
+				ErrorMessage:    fmt.Sprintf("Rate limiter error: %v", err),
+				Attempts:        attempt,
+				RetryDelayTotal: retryDelay.Milliseconds(),
+				Timestamp:       time.Now(),
+				CheckedAt:       time.Now().UTC().Format(time.RFC3339),
+			}
+		}
+
+		result := HealthCheckResult{
+			Domain:          domain,
+			URL:             redactURL(domain),
+			Attempts:        attempt + 1,
+			RetryDelayTotal: retryDelay.Milliseconds(),
+			WarmedUp:        warmedUp,
+			ResolvedIPs:     resolvedIPs,
+			DNSResolveTime:  resolveTime,
+			Timestamp:       time.Now(),
+			CheckedAt:       time.Now().UTC().Format(time.RFC3339),
+		}
+
+		checkURL := m.checkURL(domain)
+		result.URL = redactURL(checkURL)
+
+		result.IsSSL = strings.HasPrefix(checkURL, "https://")
+
+		var startTime, firstByte, dnsStart, connectStart, tlsStart time.Time
+		var traceMu sync.Mutex
+		trace := &httptrace.ClientTrace{
+			DNSStart: func(httptrace.DNSStartInfo) {
+				dnsStart = time.Now()
+			},
+			DNSDone: func(httptrace.DNSDoneInfo) {
+				result.DNSTime = time.Since(dnsStart).Milliseconds()
+			},
+			// Dual-stack dials can race two connects, so these hooks may run concurrently
+			ConnectStart: func(string, string) {
+				traceMu.Lock()
+				defer traceMu.Unlock()
+				if connectStart.IsZero() {
+					connectStart = time.Now()
+				}
+			},
+			ConnectDone: func(_, _ string, err error) {
+				traceMu.Lock()
+				defer traceMu.Unlock()
+				if err == nil && result.ConnectTime == 0 {
+					result.ConnectTime = time.Since(connectStart).Milliseconds()
+				}
+			},
+			TLSHandshakeStart: func() {
+				tlsStart = time.Now()
+			},
+			TLSHandshakeDone: func(tls.ConnectionState, error) {
+				result.TLSTime = time.Since(tlsStart).Milliseconds()
+			},
+			GotConn: func(info httptrace.GotConnInfo) {
+				result.SourceAddress = info.Conn.LocalAddr().String()
+			},
+			GotFirstResponseByte: func() {
+				firstByte = time.Now()
+			},
+		}
+
+		result.Method = settings.CheckMethod()
+		timeout := settings.RequestTimeout(m.config.Timeout)
+		result.Timeout = timeout.Milliseconds()
+
+		// The per-attempt deadline, not the shared client timeout, bounds the
+		// check so a domain can be given more (or less) time than the rest
+		reqCtx, cancel := context.WithTimeout(ctx, timeout)
+
+		req, err := http.NewRequestWithContext(httptrace.WithClientTrace(reqCtx, trace), result.Method, checkURL, nil)
+		if err != nil {
+			cancel()
+			result.Status = StatusDown
+			result.ErrorMessage = fmt.Sprintf("Failed to create request: %v", err)
+			lastResult = result
+
+			if !IsRetryableError(err, 0) || attempt == retryConfig.MaxRetries {
+				m.logger.Error("Request creation failed",
+					zap.String("domain", result.Domain),
+					zap.Error(err))
+				return result
+			}
+
+			backoff := retryConfig.CalculateBackoff(attempt)
+
+			retryDelay += backoff
+			if err := sleepBackoff(ctx, backoff); err != nil {
+				result.ErrorMessage = "Context cancelled during retry"
+				return result
+			}
+			continue
+		}
+
+		if settings.InsecureSkipVerify {
+			result.Insecure = true
+			m.logger.Warn("Checking with TLS verification disabled",
+				zap.String("domain", domain))
+		}
+
+		m.applyCheckHeaders(req, settings)
+		if req.Host != "" {
+			result.HostHeader = req.Host
+		}
+
+		startTime = time.Now()
+		resp, err := m.clientFor(settings).Do(req)
+		duration := time.Since(startTime)
+		result.ResponseTime = duration.Milliseconds()
+
+		if err != nil {
+			cancel()
+			result.Status = StatusDown
+			result.ErrorMessage = fmt.Sprintf("Request failed: %v", err)
+			result.FailureReason = classifyFailure(err)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				result.ErrorMessage = fmt.Sprintf("Request timed out after %s: %v", timeout, err)
+			}
+			lastResult = result
+
+			// A bad certificate won't fix itself between retries
+			if tlsErr := classifyTLSError(err); tlsErr != "" {
+				result.TLSError = tlsErr
+				result.ErrorMessage = fmt.Sprintf("TLS certificate error: %s", tlsErr)
+				m.logger.Warn("TLS certificate validation failed",
+					zap.String("domain", domain),
+					zap.String("tls_error", tlsErr))
+				return result
+			}
+
+			if !IsRetryableError(err, 0) {
+				return result
+			}
+
+			if attempt == retryConfig.MaxRetries {
+				m.logger.Warn("Max retries reached",
+					zap.String("domain", domain),
+					zap.Int("attempts", attempt+1))
+				return result
+			}
+
+			backoff := retryConfig.CalculateBackoff(attempt)
+
+			retryDelay += backoff
+			if err := sleepBackoff(ctx, backoff); err != nil {
+				result.ErrorMessage = "Context cancelled during retry"
+				return result
+			}
+			continue
+		}
+
+		// Drain and close now rather than deferring, so each attempt's
+		// connection goes back to the pool before the next retry
+		var body []byte
+		if settings.Validator != "" || settings.BodyMatch != "" {
+			body, _ = io.ReadAll(io.LimitReader(resp.Body, settings.BodyReadLimit()))
+		}
+		drained, _ := io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		cancel()
+
+		// ResponseTime stops at the headers; the two byte timings let a fast
+		// but slow-streaming endpoint be told apart from a truly fast one
+		if !firstByte.IsZero() {
+			result.TimeToFirstByte = firstByte.Sub(startTime).Milliseconds()
+		} else {
+			result.TimeToFirstByte = result.ResponseTime
+		}
+		result.TimeToLastByte = time.Since(startTime).Milliseconds()
+
+		result.StatusCode = resp.StatusCode
+		result.FinalURL = redactURL(resp.Request.URL.String())
+		result.ContentLength = resp.ContentLength
+		if result.ContentLength < 0 {
+			// Chunked or close-delimited responses don't declare a length,
+			// so report what was actually read
+			result.ContentLength = int64(len(body)) + drained
+		}
+
+		if resp.TLS != nil && settings.ServerName != "" {
+			result.ServerName = resp.TLS.ServerName
+		}
+
+		if resp.TLS != nil {
+			result.TLSVersion = tls.VersionName(resp.TLS.Version)
+			result.CipherSuite = tls.CipherSuiteName(resp.TLS.CipherSuite)
+		}
+
+		if result.IsSSL && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+			cert := resp.TLS.PeerCertificates[0]
+			result.SSLExpiry = cert.NotAfter.UTC().Format(time.RFC3339)
+			daysLeft := int(time.Until(cert.NotAfter).Hours() / 24)
+			result.SSLDaysLeft = daysLeft
+			result.CertDNSNames = cert.DNSNames
+
+			// The name the cert must cover: the SNI override when set, else
+			// the Host override, else the host finally connected to
+			hostname := resp.Request.URL.Hostname()
+			if settings.ServerName != "" {
+				hostname = settings.ServerName
+			} else if settings.HostHeader != "" {
+				hostname = settings.HostHeader
+			}
+			match := cert.VerifyHostname(hostname) == nil
+			result.HostnameMatch = &match
+			if !match {
+				m.logger.Warn("SSL certificate does not cover hostname",
+					zap.String("domain", result.Domain),
+					zap.String("hostname", hostname),
+					zap.Strings("dns_names", cert.DNSNames))
+			}
+
+			if daysLeft < SSLExpiryWarning {
+				m.logger.Warn("SSL certificate expiring soon",
+					zap.String("domain", result.Domain),
+					zap.Int("days_left", daysLeft))
+			}
+		}
+
+		if m.config.SamplesPerCheck > 1 {
+			m.sampleLatency(ctx, checkURL, settings, &result)
+		}
+
+		result.ExpectedStatus = settings.ExpectStatus
+		result.FastThreshold = settings.FastUnder()
+		result.DegradedThreshold = settings.DegradedAfter()
+		result.DownThreshold = settings.DownAfter()
+		result.Status = m.determineStatus(resp.StatusCode, result.ResponseTime, settings)
+
+		if settings.BodyMatch != "" && result.Status != StatusDown {
+			matched := bytes.Contains(body, []byte(settings.BodyMatch))
+			result.MatchedKeyword = &matched
+			if !matched {
+				result.Status = settings.BodyMismatchStatus()
+				result.ErrorMessage = fmt.Sprintf("Response body does not contain %q", settings.BodyMatch)
+			}
+		}
+
+		// The validator's verdict replaces the status, so it can clear a
+		// latency or body_match downgrade too; a response that is already
+		// down is not worth validating
+		if settings.Validator != "" && result.Status != StatusDown {
+			result.Status, result.ErrorMessage = m.runValidator(ctx, settings.Validator, result, body)
+		}
+
+		// The TLS policy is applied after the validator so its verdict can't
+		// hide a connection below MIN_TLS_VERSION
+		if minVersion := m.config.MinTLSVersion; minVersion != 0 && resp.TLS != nil && resp.TLS.Version < minVersion && result.Status == StatusUp {
+			result.Status = StatusDegraded
+			result.ErrorMessage = fmt.Sprintf("Negotiated %s, below the minimum %s", result.TLSVersion, tls.VersionName(minVersion))
+		}
+		lastResult = result
+
+		if result.Status == StatusUp {
+			if settings.DegradedOnRetry && attempt > 0 {
+				result.Status = StatusDegraded
+				result.ErrorMessage = fmt.Sprintf("Succeeded after %d attempts", attempt+1)
+			}
+			return result
+		}
+
+		if !IsRetryableError(nil, result.StatusCode) {
+			return result
+		}
+
+		if attempt == retryConfig.MaxRetries {
+			break
+		}
+
+		backoff := retryConfig.CalculateBackoff(attempt)
+		if wait := retryAfter(resp, retryAfterLimit(ctx, retryConfig)); wait > backoff {
+			m.logger.Debug("Honoring Retry-After",
+				zap.String("domain", domain),
+				zap.Duration("wait", wait))
+			backoff = wait
+		}
+
+		retryDelay += backoff
+		if err := sleepBackoff(ctx, backoff); err != nil {
+			result.ErrorMessage = "Context cancelled during retry"
+			return result
+		}
+	}
+
+	return lastResult
+}
+
+// secretQueryParams are substrings of query parameter names whose values
+// redactURL masks, e.g. token, api_key or X-Amz-Signature
+var secretQueryParams = []string{"token", "key", "secret", "pass", "auth", "sig"}
+
+// redactURL masks any password embedded in raw, and the values of query
+// parameters that look like credentials, so they can't leak into reports.
+// raw may be a bare domain entry without a scheme.
+func redactURL(raw string) string {
+	target, bare := raw, !strings.Contains(raw, "://")
+	if bare {
+		target = "//" + raw
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return raw
+	}
+
+	query, changed := u.Query(), false
+	for name := range query {
+		lower := strings.ToLower(name)
+		for _, secret := range secretQueryParams {
+			if strings.Contains(lower, secret) {
+				query[name] = []string{"xxxxx"}
+				changed = true
+				break
+			}
+		}
+	}
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+
+	redacted := u.Redacted()
+	if bare {
+		redacted = strings.TrimPrefix(redacted, "//")
+	}
+	return redacted
+}
+
+// sampleLatency takes SamplesPerCheck-1 further timed requests after a check's
+// first response and records the latency spread. ResponseTime becomes the
+// median so a single slow sample doesn't flip the status to degraded. Failed
+// samples are left out of the statistics.
+func (m *UptimeMonitor) sampleLatency(ctx context.Context, checkURL string, settings DomainConfig, result *HealthCheckResult) {
+	samples := []int64{result.ResponseTime}
+
+	for i := 1; i < m.config.SamplesPerCheck; i++ {
+		if err := m.config.RateLimiter.Wait(ctx); err != nil {
+			break
+		}
+
+		req, err := http.NewRequestWithContext(ctx, settings.CheckMethod(), checkURL, nil)
+		if err != nil {
+			break
+		}
+		m.applyCheckHeaders(req, settings)
+
+		startTime := time.Now()
+		resp, err := m.clientFor(settings).Do(req)
+		elapsed := time.Since(startTime).Milliseconds()
+		if err != nil {
+			m.logger.Debug("Latency sample failed",
+				zap.String("domain", result.Domain),
+				zap.Error(err))
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		samples = append(samples, elapsed)
+	}
+
+	slices.Sort(samples)
+	n := len(samples)
+	result.Samples = n
+	result.LatencyMin = samples[0]
+	result.LatencyMedian = samples[n/2]
+	if n%2 == 0 {
+		result.LatencyMedian = (samples[n/2-1] + samples[n/2]) / 2
+	}
+	result.LatencyP95 = samples[int(math.Ceil(0.95*float64(n)))-1]
+	result.LatencyMax = samples[n-1]
+	result.ResponseTime = result.LatencyMedian
+}
+
+// resolveHost looks up the host of checkURL, returning its addresses and how
+// long the lookup took in ms. IP literals are returned without a lookup.
+func (m *UptimeMonitor) resolveHost(ctx context.Context, checkURL string) ([]string, int64, error) {
+	u, err := url.Parse(checkURL)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return []string{ip.String()}, 0, nil
+	}
+
+	startTime := time.Now()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	elapsed := time.Since(startTime).Milliseconds()
+	if err != nil {
+		return nil, elapsed, err
+	}
+
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP.String())
+	}
+	return ips, elapsed, nil
+}
+
+// warmUp sends one untimed request so cold caches or serverless backends don't
+// skew the timed check. Failures are ignored; the timed check reports them.
+func (m *UptimeMonitor) warmUp(ctx context.Context, domain string, settings DomainConfig) bool {
+	if err := m.config.RateLimiter.Wait(ctx); err != nil {
+		return false
+	}
+
+	req, err := http.NewRequestWithContext(ctx, settings.CheckMethod(), m.checkURL(domain), nil)
+	if err != nil {
+		return false
+	}
+	m.applyCheckHeaders(req, settings)
+
+	resp, err := m.clientFor(settings).Do(req)
+	if err != nil {
+		m.logger.Debug("Warm-up request failed",
+			zap.String("domain", domain),
+			zap.Error(err))
+		return false
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return true
+}
+
+// applyCheckHeaders sets the User-Agent, the domain's configured headers and
+// its credentials on a check request. A Host header overrides the request
+// host, and host_header takes precedence over it.
+func (m *UptimeMonitor) applyCheckHeaders(req *http.Request, settings DomainConfig) {
+	req.Header.Set("User-Agent", m.config.UserAgent)
+
+	for key, values := range settings.Headers {
+		if strings.EqualFold(key, "Host") {
+			if len(values) > 0 {
+				req.Host = values[0]
+			}
+			continue
+		}
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if settings.HostHeader != "" {
+		req.Host = settings.HostHeader
+	}
+
+	if settings.Auth != nil {
+		settings.Auth.apply(req)
+	}
+}
+
+// checkURL returns the URL checked for domain, adding the default scheme when it has none
+func (m *UptimeMonitor) checkURL(domain string) string {
+	if strings.HasPrefix(domain, "http://") || strings.HasPrefix(domain, "https://") {
+		return domain
+	}
+	return m.config.DefaultScheme + "://" + domain
+}
+
+// determineStatus determines the status of a domain based on the response code and response time.
+// A response slower than the down threshold (when set) is down regardless of its status code.
+// When expected codes are configured, any other code is down; otherwise the code range decides.
+func (m *UptimeMonitor) determineStatus(statusCode int, responseTime int64, settings DomainConfig) string {
+	if downAfter := settings.DownAfter(); downAfter > 0 && responseTime >= downAfter {
+		return StatusDown
+	}
+
+	if len(settings.ExpectStatus) > 0 {
+		if !slices.Contains(settings.ExpectStatus, statusCode) {
+			return StatusDown
+		}
+		if responseTime >= settings.DegradedAfter() {
+			return StatusDegraded
+		}
+		return StatusUp
+	}
+
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		if responseTime >= settings.DegradedAfter() {
+			return StatusDegraded
+		}
+		return StatusUp
+	case statusCode >= 300 && statusCode < 400:
+		return StatusUp
+	case statusCode >= 400 && statusCode < 500:
+		return StatusDegraded
+	default:
+		return StatusDown
+	}
+}
+
+// concurrency returns how many checks may run at once for a run over the given
+// number of domains: MONITOR_CONCURRENT, raised to at least 1 and capped at
+// the domain count so no idle slots are reserved
+func (m *UptimeMonitor) concurrency(domains int) int {
+	concurrent := m.config.Concurrent
+	if concurrent < 1 {
+		m.logger.Warn("Concurrency below 1, using default",
+			zap.Int("configured", concurrent),
+			zap.Int("default", DefaultConcurrent))
+		concurrent = DefaultConcurrent
+	}
+	if domains > 0 && concurrent > domains {
+		m.logger.Debug("Concurrency capped at domain count",
+			zap.Int("configured", concurrent),
+			zap.Int("domains", domains))
+		concurrent = domains
+	}
+	return concurrent
+}
+
+// RunCheck runs a health check on all domains in the configuration. Domains are
+// checked in dependency order; a domain whose dependency is down is reported as
+// blocked instead of being checked.
+func (m *UptimeMonitor) RunCheck(ctx context.Context) (*MonitorReport, error) {
+	startTime := time.Now()
+
+	exp := m.expandSRV(ctx, m.config.Domains)
+	domains := exp.domains
+
+	m.srvMu.Lock()
+	m.srvGroups = exp.groups
+	m.srvMu.Unlock()
+
+	configs := make(map[string]DomainConfig, len(domains))
+	for _, domain := range domains {
+		configs[domain] = m.domainSettings(domain)
+	}
+
+	levels, err := dependencyLevels(domains, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(domains))
+	for i, domain := range domains {
+		index[domain] = i
+	}
+
+	results := make([]HealthCheckResult, len(domains))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, m.concurrency(len(domains)))
+
+	// Checks still running when the run deadline passes are abandoned; mu
+	// keeps them from writing into results once the report is being built
+	var mu sync.Mutex
+	finished := make([]bool, len(domains))
+	abandoned := false
+
+	var unreached, unfinished []string
+	for _, level := range levels {
+		if abandoned {
+			for _, i := range level {
+				if err, ok := exp.errors[domains[i]]; ok {
+					results[i] = srvFailedResult(domains[i], err)
+					continue
+				}
+				results[i] = notStartedResult(domains[i], ctx.Err())
+				unreached = append(unreached, domains[i])
+			}
+			continue
+		}
+
+		// Slots are taken here in priority order rather than inside each
+		// goroutine, so when the run deadline hits it is the low-priority
+		// domains that never start
+		var started []int
+		for _, i := range m.byPriority(domains, level) {
+			domain := domains[i]
+
+			if err, ok := exp.errors[domain]; ok {
+				results[i] = srvFailedResult(domain, err)
+				continue
+			}
+
+			if parent, ok := m.failedDependency(domain, results, index); ok {
+				results[i] = blockedResult(domain, parent, results[index[parent]].Status)
+				continue
+			}
+
+			// The slot is released while the check sleeps between retries so
+			// slow retriers don't keep healthy domains waiting
+			slot := &checkSlot{sem: semaphore}
+			if err := slot.acquire(ctx); err != nil {
+				results[i] = notStartedResult(domain, err)
+				unreached = append(unreached, domain)
+				continue
+			}
+
+			started = append(started, i)
+			wg.Add(1)
+			go func(index int, d string) {
+				defer wg.Done()
+				defer slot.release()
+
+				result := m.checkWithBreaker(withSlot(ctx, slot), d)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if !abandoned {
+					results[index] = result
+					finished[index] = true
+				}
+			}(i, domain)
+		}
+
+		if !waitForChecks(ctx, &wg, RunDeadlineGrace) {
+			mu.Lock()
+			abandoned = true
+			for _, i := range started {
+				if !finished[i] {
+					results[i] = unfinishedResult(domains[i])
+					unfinished = append(unfinished, domains[i])
+				}
+			}
+			mu.Unlock()
+		}
+	}
+
+	if len(unreached) > 0 {
+		m.logger.Warn("Run deadline reached before all domains were checked",
+			zap.Strings("unreached", unreached))
+	}
+	if len(unfinished) > 0 {
+		m.logger.Warn("Run deadline reached before all checks finished",
+			zap.Strings("unfinished", unfinished))
+	}
+
+	for i, domain := range domains {
+		if group, ok := exp.groups[domain]; ok {
+			results[i].Group = group
+		}
+	}
+
+	report := m.generateReport(results)
+	report.RunDuration = time.Since(startTime).Milliseconds()
+	report.Unreached = unreached
+	report.Unfinished = unfinished
+	if len(exp.targets) > 0 {
+		report.SRVTargets = exp.targets
+	}
+
+	return report, nil
+}
+
+// RunDeadlineGrace is how long RunCheck waits, once the run deadline has
+// passed, for in-flight checks to notice and return their own results
+const RunDeadlineGrace = 2 * time.Second
+
+// waitForChecks waits for wg, giving up grace after ctx is done. It reports
+// whether every check finished.
+func waitForChecks(ctx context.Context, wg *sync.WaitGroup, grace time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+	}
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(grace):
+		return false
+	}
+}
+
+// notStartedResult is the result for a domain whose check never began
+func notStartedResult(domain string, err error) HealthCheckResult {
+	return HealthCheckResult{
+		Domain:       domain,
+		URL:          redactURL(domain),
+		Status:       StatusDown,
+		ErrorMessage: fmt.Sprintf("Check not started: %v", err),
+		Timestamp:    time.Now(),
+		CheckedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// unfinishedResult is the result for a check abandoned at the run deadline
+func unfinishedResult(domain string) HealthCheckResult {
+	return HealthCheckResult{
+		Domain:        domain,
+		URL:           redactURL(domain),
+		Status:        StatusDown,
+		ErrorMessage:  "Check did not finish before the run deadline",
+		FailureReason: FailureTimeout,
+		Timestamp:     time.Now(),
+		CheckedAt:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// byPriority returns the domain indexes of a dependency level ordered by
+// descending priority, keeping the configured order for equal priorities
+func (m *UptimeMonitor) byPriority(domains []string, level []int) []int {
+	ordered := append([]int(nil), level...)
+	sort.SliceStable(ordered, func(a, b int) bool {
+		return m.domainSettings(domains[ordered[a]]).Priority >
+			m.domainSettings(domains[ordered[b]]).Priority
+	})
+	return ordered
+}
+
+// checkWithBreaker checks domain unless its circuit is open, in which case an
+// immediate down result is returned without touching the network
+func (m *UptimeMonitor) checkWithBreaker(ctx context.Context, domain string) HealthCheckResult {
+	// During planned work the domain is checked whatever its circuit says,
+	// and the result is reported as maintenance without touching the breaker
+	if _, ok := m.activeMaintenance(domain, time.Now()); ok {
+		result := m.CheckDomain(ctx, domain)
+		m.applyMaintenance(&result, time.Now())
+		return result
+	}
+
+	if !m.breaker.Allow(domain) {
+		return HealthCheckResult{
+			Domain:       domain,
+			URL:          redactURL(domain),
+			Status:       StatusDown,
+			ErrorMessage: "Circuit open: skipped after repeated failures",
+			CircuitOpen:  true,
+			Timestamp:    time.Now(),
+			CheckedAt:    time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+
+	wasOpen := m.breaker.IsOpen(domain)
+	result := m.CheckDomain(ctx, domain)
+
+	// Failures during planned work say nothing about the domain's health
+	if m.applyMaintenance(&result, time.Now()) {
+		return result
+	}
+	m.breaker.Record(domain, result.Status == StatusDown)
+
+	switch isOpen := m.breaker.IsOpen(domain); {
+	case !wasOpen && isOpen:
+		m.logger.Warn("Circuit opened", zap.String("domain", domain))
+	case wasOpen && !isOpen:
+		m.logger.Info("Circuit closed", zap.String("domain", domain))
+	}
+
+	return result
+}
+
+// failedDependency returns the first dependency of domain that is down or blocked
+func (m *UptimeMonitor) failedDependency(domain string, results []HealthCheckResult, index map[string]int) (string, bool) {
+	for _, parent := range m.domainSettings(domain).DependsOn {
+		status := results[index[parent]].Status
+		if status == StatusDown || status == StatusBlocked {
+			return parent, true
+		}
+	}
+	return "", false
+}
+
+// blockedResult builds the result for a check skipped because parent failed
+func blockedResult(domain, parent, parentStatus string) HealthCheckResult {
+	return HealthCheckResult{
+		Domain:       domain,
+		URL:          redactURL(domain),
+		Status:       StatusBlocked,
+		ErrorMessage: fmt.Sprintf("Skipped: dependency %s is %s", parent, parentStatus),
+		BlockedBy:    parent,
+		Timestamp:    time.Now(),
+		CheckedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func (m *UptimeMonitor) generateReport(results []HealthCheckResult) *MonitorReport {
+	var totalLatency int64
+	var upCount, downCount, degradedCount, blockedCount, maintenanceCount int
+	var flaky []string
+
+	for _, result := range results {
+		if result.Status == StatusBlocked {
+			// Blocked checks never ran, so they don't count as outages or latency samples
+			blockedCount++
+			continue
+		}
+		if result.Status == StatusMaintenance {
+			// Planned work is excluded from uptime, like a check that never ran
+			maintenanceCount++
+			continue
+		}
+
+		totalLatency += result.ResponseTime
+
+		if result.Attempts >= FlakyAttempts && result.Status != StatusDown {
+			flaky = append(flaky, result.Domain)
+			m.logger.Warn("Domain recovered only after retries",
+				zap.String("domain", result.Domain),
+				zap.Int("attempts", result.Attempts))
+		}
+
+		switch result.Status {
+		case StatusUp:
+			upCount++
+		case StatusDown:
+			downCount++
+		case StatusDegraded:
+			degradedCount++
+		}
+	}
+
+	checked := len(results) - blockedCount - maintenanceCount
+
+	avgLatency := float64(0)
+	if checked > 0 {
+		avgLatency = float64(totalLatency) / float64(checked)
+	}
+
+	uptimePercent := float64(0)
+	if checked > 0 {
+		uptimePercent = float64(upCount) / float64(checked) * 100
+	}
+
+	return &MonitorReport{
+		Service:        reportService,
+		Environment:    m.config.Environment,
+		TotalChecks:    len(results),
+		Uptime:         upCount,
+		Downtime:       downCount,
+		Degraded:       degradedCount,
+		Blocked:        blockedCount,
+		Maintenance:    maintenanceCount,
+		UptimePercent:  uptimePercent,
+		AverageLatency: avgLatency,
+		FlakyDomains:   flaky,
+		HealthScore:    m.healthScore(results, uptimePercent),
+		Timestamp:      time.Now().UTC(),
+		Results:        results,
+	}
+}
+
+// SaveReport saves the report to a file and sends an email if the directory creation fails.
+func (m *UptimeMonitor) SaveReport(report *MonitorReport) (string, error) {
+	filename, err := m.reportPath(report, "json")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		m.logger.Error("Failed to create output directory, sending via email", zap.Error(err))
+		if emailErr := m.SendEmailOnFailure(report, nil); emailErr != nil {
+			m.logger.Error("Failed to send email", zap.Error(emailErr))
+		}
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if m.config.OutputCompression == CompressionGzip {
+		filename += ".gz"
+	}
+	report.ReportFile = filename
+
+	jsonData, err := m.encodeReport(report)
+	if err != nil {
+		report.ReportFile = ""
+		m.logger.Error("Failed to marshal JSON, sending via email", zap.Error(err))
+		if emailErr := m.SendEmailOnFailure(report, nil); emailErr != nil {
+			m.logger.Error("Failed to send email", zap.Error(emailErr))
+		}
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		report.ReportFile = ""
+		m.logger.Error("Failed to write file, sending via email", zap.Error(err))
+		if emailErr := m.SendEmailOnFailure(report, nil); emailErr != nil {
+			m.logger.Error("Failed to send email", zap.Error(emailErr))
+		}
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	m.logger.Info("Report saved", zap.String("file", filename))
+	m.pruneReports(filename)
+	return filename, nil
+}
+
+// updateSavedReport rewrites the JSON report saved earlier in the run, so
+// fields filled in after saving, such as SubmittedTo, reach the file too
+func (m *UptimeMonitor) updateSavedReport(report *MonitorReport) error {
+	if report.ReportFile == "" {
+		return nil
+	}
+
+	jsonData, err := m.encodeReport(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	if err := os.WriteFile(report.ReportFile, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// encodeReport serializes the report for disk using the configured indentation and compression
+func (m *UptimeMonitor) encodeReport(report *MonitorReport) ([]byte, error) {
+	var jsonData []byte
+	var err error
+	if m.config.OutputIndent {
+		jsonData, err = json.MarshalIndent(report, "", "  ")
+	} else {
+		jsonData, err = json.Marshal(report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if m.config.OutputCompression != CompressionGzip {
+		return jsonData, nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(jsonData); err != nil {
+		return nil, fmt.Errorf("failed to compress report: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadReport reads a report written by SaveReport, decompressing .gz files
+func LoadReport(path string) (*MonitorReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress report: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var report MonitorReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
+
+// EmailAttachment is a file attached to an email message
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// BuildEmailMessage builds a multipart email message with both plain text and HTML parts.
+// With attachments the alternative parts are wrapped in a multipart/mixed message.
+// Bcc recipients never appear in the headers; pass them to sendMail only.
+func BuildEmailMessage(from string, to, cc []string, subject string, htmlBody string, plainBody string, attachments ...EmailAttachment) []byte {
+	boundary := "boundary_" + fmt.Sprint(time.Now().UnixNano())
+	mixedBoundary := "mixed_" + boundary
+
+	var msg []byte
+	msg = fmt.Appendf(msg, "From: Uptime Monitor <%s>\r\n", from)
+	msg = fmt.Appendf(msg, "To: %s\r\n", strings.Join(to, ","))
+	if len(cc) > 0 {
+		msg = fmt.Appendf(msg, "Cc: %s\r\n", strings.Join(cc, ","))
+	}
+	msg = fmt.Appendf(msg, "Subject: %s\r\n", subject)
+	msg = fmt.Appendf(msg, "MIME-Version: 1.0\r\n")
+	if len(attachments) > 0 {
+		msg = fmt.Appendf(msg, "Content-Type: multipart/mixed; boundary=%s\r\n", mixedBoundary)
+		msg = fmt.Appendf(msg, "\r\n")
+		msg = fmt.Appendf(msg, "--%s\r\n", mixedBoundary)
+	}
+	msg = fmt.Appendf(msg, "Content-Type: multipart/alternative; boundary=%s\r\n", boundary)
+	msg = fmt.Appendf(msg, "\r\n")
+
+	// Plain text section (for clients that don't support HTML)
+	msg = fmt.Appendf(msg, "--%s\r\n", boundary)
+	msg = fmt.Appendf(msg, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg = fmt.Appendf(msg, "%s\r\n", plainBody)
+
+	// HTML section
+	msg = fmt.Appendf(msg, "\r\n--%s\r\n", boundary)
+	msg = fmt.Appendf(msg, "Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg = fmt.Appendf(msg, "%s\r\n", htmlBody)
+
+	// Closing boundary
+	msg = fmt.Appendf(msg, "\r\n--%s--\r\n", boundary)
+
+	if len(attachments) == 0 {
+		return msg
+	}
+
+	for _, a := range attachments {
+		msg = fmt.Appendf(msg, "\r\n--%s\r\n", mixedBoundary)
+		msg = fmt.Appendf(msg, "Content-Type: %s; name=%q\r\n", a.ContentType, a.Filename)
+		msg = fmt.Appendf(msg, "Content-Disposition: attachment; filename=%q\r\n", a.Filename)
+		msg = fmt.Appendf(msg, "Content-Transfer-Encoding: base64\r\n\r\n")
+
+		// RFC 2045 limits encoded lines to 76 characters
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			msg = fmt.Appendf(msg, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		msg = fmt.Appendf(msg, "%s\r\n", encoded)
+	}
+	msg = fmt.Appendf(msg, "\r\n--%s--\r\n", mixedBoundary)
+
+	return msg
+}
+
+// BuildPlainEmailMessage builds a single-part plain text email message.
+func BuildPlainEmailMessage(from string, to, cc []string, subject string, body string) []byte {
+	var msg []byte
+	msg = fmt.Appendf(msg, "From: Uptime Monitor <%s>\r\n", from)
+	msg = fmt.Appendf(msg, "To: %s\r\n", strings.Join(to, ","))
+	if len(cc) > 0 {
+		msg = fmt.Appendf(msg, "Cc: %s\r\n", strings.Join(cc, ","))
+	}
+	msg = fmt.Appendf(msg, "Subject: %s\r\n", subject)
+	msg = fmt.Appendf(msg, "MIME-Version: 1.0\r\n")
+	msg = fmt.Appendf(msg, "Content-Type: text/plain; charset=UTF-8\r\n")
+	msg = fmt.Appendf(msg, "\r\n")
+	msg = fmt.Appendf(msg, "%s\r\n", body)
+
+	return msg
+}
+
+// reportAttachmentName is the filename of the JSON report attached to emails
+const reportAttachmentName = "uptime_report.json"
+
+// SendEmailOnFailure sends report via email when JSON file creation fails.
+// Each recipient group receives its own message in its configured format.
+func (m *UptimeMonitor) SendEmailOnFailure(report *MonitorReport, head *string) error {
+	if m.config.NotificationsDisabled {
+		m.logger.Info("Email suppressed: notifications are disabled")
+		return nil
+	}
+
+	groups := m.emailGroups()
+	if !m.emailConfigured(groups) {
+		return nil
+	}
+
+	subject := "Uptime Monitor File Report Creation Failed"
+	if head != nil {
+		subject = *head
+	}
+	return m.sendReportEmail(report, groups, subject, "Failed to create JSON file for report")
+}
+
+// sendReportEmail sends report to each recipient group in its configured
+// format. intro opens the plain text body of full-format messages.
+func (m *UptimeMonitor) sendReportEmail(report *MonitorReport, groups []EmailGroup, subject, intro string) error {
+	jsonBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON data: %w", err)
+	}
+
+	summary, err := renderEmailTemplate(DefaultSummaryTemplate, "", report)
+	if err != nil {
+		return err
+	}
+
+	plainBody := fmt.Sprintf(
+		"%s\n\n"+
+			"%s\n\n"+
+			"The full report is attached as %s.\n",
+		intro,
+		summary,
+		reportAttachmentName,
+	)
+	attachment := EmailAttachment{
+		Filename:    reportAttachmentName,
+		ContentType: "application/json",
+		Data:        jsonBytes,
+	}
+
+	var htmlBody string
+	var errs []error
+
+	for _, group := range groups {
+		groupSubject, err := renderEmailTemplate(group.Subject, subject, report)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", group.Name, err))
+			continue
+		}
+
+		var message []byte
+		if group.Format == EmailFormatSummary {
+			bodyTemplate := group.Body
+			if bodyTemplate == "" {
+				bodyTemplate = DefaultSummaryTemplate
+			}
+
+			body, err := renderEmailTemplate(bodyTemplate, "", report)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", group.Name, err))
+				continue
+			}
+			message = BuildPlainEmailMessage(m.config.EmailUser, group.To, group.Cc, groupSubject, body)
+		} else {
+			body, err := renderEmailTemplate(group.Body, plainBody, report)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", group.Name, err))
+				continue
+			}
+
+			if htmlBody == "" {
+				htmlBody, err = BuildHTMLReport(report, subject, HTMLReportOptions{
+					MaxRows:     m.config.HTMLMaxRows,
+					InlineChart: m.config.ChartInline,
+					History:     m.history.Reports(),
+					Chart:       m.config.Chart,
+					Storage:     m.chartStorage,
+					Logger:      m.logger,
+				})
+				if err != nil {
+					htmlBody = "<pre>" + plainBody + "</pre>"
+				}
+			}
+
+			message = BuildEmailMessage(m.config.EmailUser, group.To, group.Cc, groupSubject, htmlBody, body, attachment)
+		}
+
+		if err := m.sendMail(group.recipients(), message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", group.Name, err))
+			continue
+		}
+
+		m.logger.Info("Email sent with JSON data",
+			zap.String("group", group.Name),
+			zap.String("format", group.Format),
+			zap.Int("data_size", len(jsonBytes)),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+// emailGroups returns the recipient groups: EMAIL_TO as a full-format group
+// followed by any EMAIL_GROUPS entries
+func (m *UptimeMonitor) emailGroups() []EmailGroup {
+	var groups []EmailGroup
+	if strings.Join(m.config.EmailTo, "") != "" {
+		groups = append(groups, EmailGroup{
+			Name:   "default",
+			To:     m.config.EmailTo,
+			Cc:     m.config.EmailCC,
+			Bcc:    m.config.EmailBCC,
+			Format: EmailFormatFull,
+		})
+	}
+	return append(groups, m.config.EmailGroups...)
+}
+
+// emailConfigured reports whether there are credentials, a sender and at
+// least one recipient group to send email with
+func (m *UptimeMonitor) emailConfigured(groups []EmailGroup) bool {
+	return (m.config.EmailAuth != "" || m.smtpTokens != nil) && len(groups) > 0 && m.config.EmailUser != ""
+}
+
+// sendMail delivers a prepared message through the configured SMTP server
+func (m *UptimeMonitor) sendMail(to []string, message []byte) error {
+	client, err := dialSMTP(m.config.SMTPHost, m.config.SMTPPort, m.config.SMTPTLSMode)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer client.Close()
+
+	auth, err := m.smtpAuth()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	if err := deliverSMTP(client, auth, m.config.EmailUser, to, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// smtpAuth returns XOAUTH2 credentials when OAuth is configured, and PLAIN
+// with EMAIL_AUTH otherwise
+func (m *UptimeMonitor) smtpAuth() (smtp.Auth, error) {
+	if m.smtpTokens == nil {
+		return smtp.PlainAuth("", m.config.EmailUser, m.config.EmailAuth, m.config.SMTPHost), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	token, err := m.smtpTokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &xoauth2Auth{username: m.config.EmailUser, token: token}, nil
+}
+
+// SubmitToAPI submits the monitoring report to every configured API target.
+// A failing target does not prevent submission to the others; all failures
+// are returned together.
+func (m *UptimeMonitor) SubmitToAPI(ctx context.Context, report *MonitorReport) error {
+	if len(m.config.APITargets) == 0 {
+		return fmt.Errorf("failed to provide backend url")
+	}
+
+	var errs []error
+	var succeeded []string
+	for _, target := range m.config.APITargets {
+		if err := m.submitToTarget(ctx, target, report); err != nil {
+			m.logger.Error("API submission failed",
+				zap.String("target", target.Name),
+				zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", target.Name, err))
+			continue
+		}
+		succeeded = append(succeeded, target.Name)
+	}
+
+	report.SubmittedTo = succeeded
+	m.logger.Info("API submission finished",
+		zap.Strings("succeeded", succeeded),
+		zap.Int("failed", len(errs)))
+
+	return errors.Join(errs...)
+}
+
+// submitToTarget submits the report to a single API target with rate limiting and retries
+func (m *UptimeMonitor) submitToTarget(ctx context.Context, target APITarget, report *MonitorReport) error {
+	retryConfig := m.config.SubmitRetry
+	var lastErr error
+
+	// Every attempt sends the same bytes
+	jsonData, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	payload, err := encodeSubmission(jsonData, m.config.APIGzipThreshold)
+	if err != nil {
+		return fmt.Errorf("failed to compress report: %w", err)
+	}
+
+	targetURL, err := renderSubmitURL(target.URL, report)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
+		if err := m.waitRateLimit(ctx, attempt); err != nil {
+			return fmt.Errorf("rate limiter error: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, target.Method, targetURL, bytes.NewReader(payload.data))
+		if err != nil {
+			lastErr = fmt.Errorf("failed to create API request: %w", err)
+
+			if attempt == retryConfig.MaxRetries {
+				return fmt.Errorf("API submission failed after %d attempts: %w", retryConfig.MaxRetries+1, lastErr)
+			}
+
+			backoff := retryConfig.CalculateBackoff(attempt)
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+			case <-time.After(backoff):
+				continue
+			}
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if payload.gzipped {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		if m.config.APIIdempotencyHeader != "" {
+			req.Header.Set(m.config.APIIdempotencyHeader, idempotencyKey(jsonData))
+		}
+		if m.config.APISigningSecret != "" {
+			signRequest(req, payload.data, m.config.APISigningSecret, time.Now())
+		}
+		req.Header.Set("User-Agent", m.config.UserAgent)
+		if target.APIKey != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", target.APIKey))
+		}
+		for key, value := range target.Headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := m.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to submit to API: %w", err)
+
+			if attempt == retryConfig.MaxRetries {
+				return fmt.Errorf("API submission failed after %d attempts: %w", retryConfig.MaxRetries+1, lastErr)
+			}
+
+			backoff := retryConfig.CalculateBackoff(attempt)
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+			case <-time.After(backoff):
+				continue
+			}
+		}
+
+		// Read and close within the attempt so a retry can reuse the connection
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			lastErr = fmt.Errorf("API submission failed with status %d: %s", resp.StatusCode, string(body))
+
+			if !IsRetryableError(lastErr, resp.StatusCode) {
+				return lastErr
+			}
+
+			if attempt < retryConfig.MaxRetries {
+				backoff := retryConfig.CalculateBackoff(attempt)
+				if wait := retryAfter(resp, retryAfterLimit(ctx, retryConfig)); wait > backoff {
+					backoff = wait
+				}
+				select {
+				case <-ctx.Done():
+					return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+				case <-time.After(backoff):
+					continue
+				}
+			}
+
+			return lastErr
+		}
+
+		if len(target.ExpectKeys) > 0 {
+			if readErr != nil {
+				return fmt.Errorf("failed to read API response: %w", readErr)
+			}
+			if err := validateResponseKeys(body, target.ExpectKeys); err != nil {
+				return fmt.Errorf("API response validation failed: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("API submission failed after %d attempts: %w", retryConfig.MaxRetries+1, lastErr)
+}
+
+// validateResponseKeys checks that body is a JSON object containing every expected top-level key
+func validateResponseKeys(body []byte, keys []string) error {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("response is not a JSON object: %w", err)
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := payload[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("response is missing keys: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (m *UptimeMonitor) sendWebhook(ctx context.Context, url string, payload interface{}) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return m.sendWebhookRequest(ctx, "POST", url, jsonData, nil)
+}
+
+// sendWebhookRequest sends a JSON body with the given method and extra
+// headers, retrying according to WebhookRetry. Webhook URLs often embed a
+// secret (Slack paths, Telegram bot tokens), so only the host is logged and
+// errors never carry the URL.
+func (m *UptimeMonitor) sendWebhookRequest(ctx context.Context, method, target string, jsonData []byte, headers map[string]string) error {
+	retryConfig := m.config.WebhookRetry
+	var lastErr error
+
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+			case <-time.After(retryConfig.CalculateBackoff(attempt - 1)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, target, strings.NewReader(string(jsonData)))
+		if err != nil {
+			return fmt.Errorf("invalid webhook request: %w", unwrapURLError(err))
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		if m.config.WebhookSigningSecret != "" {
+			signRequest(req, jsonData, m.config.WebhookSigningSecret, time.Now())
+		}
+
+		resp, err := m.client.Do(req)
+		if err != nil {
+			lastErr = unwrapURLError(err)
+			continue
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			lastErr = fmt.Errorf("webhook failed with status %d: %s", resp.StatusCode, string(body))
+			if !IsRetryableError(nil, resp.StatusCode) {
+				return lastErr
+			}
+			continue
+		}
+
+		m.logger.Info("Notification sent successfully", zap.String("host", req.URL.Host))
+		return nil
+	}
+
+	return lastErr
+}
+
+// unwrapURLError drops the *url.Error wrapper, whose message repeats the
+// request URL and with it any token in the path or query
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// splitAddresses parses a comma-separated address list, dropping empty entries
+func splitAddresses(raw string) []string {
+	var addresses []string
+	for _, address := range strings.Split(raw, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := getEnv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := getEnv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,512 @@
+package uptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Notifier delivers a report to a notification channel. changes lists the
+// domains whose status differs from the previous run; domains without a
+// previous result are included with an empty Previous status, and domains
+// that were failing but have since been removed from the config with an empty
+// Current status, so incidents opened for them can be closed. Notify reports
+// whether it sent an alert for failing domains; NOTIFY_COOLDOWN only starts
+// for them once some notifier has. A notifier that skipped the run, or only
+// sent recoveries, returns false.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (sent bool, err error)
+}
+
+// StatusChange describes a domain whose status differs from the previous run
+type StatusChange struct {
+	Domain   string `json:"domain"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// detectChanges compares each domain's status against the previous report,
+// which may be nil on the first run
+func detectChanges(previous, current *MonitorReport) []StatusChange {
+	var changes []StatusChange
+	for _, result := range current.Results {
+		var prev HealthCheckResult
+		if previous != nil {
+			prev, _ = findResult(previous, result.Domain)
+		}
+		if prev.Status == result.Status {
+			continue
+		}
+		changes = append(changes, StatusChange{
+			Domain:   result.Domain,
+			Previous: prev.Status,
+			Current:  result.Status,
+		})
+	}
+
+	if previous != nil {
+		for _, prev := range failedResults(previous) {
+			if _, ok := findResult(current, prev.Domain); !ok {
+				changes = append(changes, StatusChange{Domain: prev.Domain, Previous: prev.Status})
+			}
+		}
+	}
+	return changes
+}
+
+// failedResults returns the results that are down or degraded
+func failedResults(report *MonitorReport) []HealthCheckResult {
+	var failed []HealthCheckResult
+	for _, result := range report.Results {
+		if result.Status == StatusDown || result.Status == StatusDegraded {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// AlertResults returns the failed results a notifier should alert on: all of
+// them, or with NOTIFY_ON_CHANGE only those whose status changed, minus
+// domains inside NOTIFY_COOLDOWN. Notifiers registered with RegisterNotifier
+// can call it from Notify to honour those settings.
+func (m *UptimeMonitor) AlertResults(report *MonitorReport, changes []StatusChange) []HealthCheckResult {
+	failed := failedResults(report)
+	if !m.config.NotifyOnChange {
+		return m.withoutCoolingDown(report, failed)
+	}
+
+	changed := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		changed[c.Domain] = true
+	}
+
+	var alerts []HealthCheckResult
+	for _, result := range failed {
+		if changed[result.Domain] {
+			alerts = append(alerts, result)
+		}
+	}
+	return m.withoutCoolingDown(report, alerts)
+}
+
+// withoutCoolingDown drops results for domains still inside NOTIFY_COOLDOWN
+func (m *UptimeMonitor) withoutCoolingDown(report *MonitorReport, results []HealthCheckResult) []HealthCheckResult {
+	if m.notifyState == nil {
+		return results
+	}
+
+	var kept []HealthCheckResult
+	for _, result := range results {
+		if !m.notifyState.coolingDown(result.Domain, report.Timestamp, m.config.NotifyCooldown) {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+// recoveredDomain is a domain that came back up after being down or degraded
+type recoveredDomain struct {
+	Domain   string
+	Previous string
+	Downtime time.Duration // zero when the start of the outage is unknown
+}
+
+// describe summarises the outage for a recovery message
+func (r recoveredDomain) describe() string {
+	if r.Downtime <= 0 {
+		return "was " + r.Previous
+	}
+	return fmt.Sprintf("was %s for %s", r.Previous, r.Downtime.Round(time.Second))
+}
+
+// recoveredDomains returns the domains that transitioned from down or
+// degraded back to up, with how long they had been failing according to the
+// history cache
+func (m *UptimeMonitor) recoveredDomains(report *MonitorReport, changes []StatusChange) []recoveredDomain {
+	var recovered []recoveredDomain
+	for _, c := range changes {
+		if c.Current != StatusUp || (c.Previous != StatusDown && c.Previous != StatusDegraded) {
+			continue
+		}
+
+		r := recoveredDomain{Domain: c.Domain, Previous: c.Previous}
+		if since := m.history.FailingSince(c.Domain); !since.IsZero() {
+			r.Downtime = report.Timestamp.Sub(since)
+		}
+		recovered = append(recovered, r)
+	}
+	return recovered
+}
+
+// RegisterNotifier adds a notification channel to the monitor
+func (m *UptimeMonitor) RegisterNotifier(n Notifier) {
+	m.notifiers = append(m.notifiers, n)
+}
+
+// registerConfiguredNotifiers registers the channels enabled in the config
+func (m *UptimeMonitor) registerConfiguredNotifiers() {
+	if m.config.SlackWebhook != "" {
+		m.RegisterNotifier(&slackNotifier{monitor: m, url: m.config.SlackWebhook})
+	}
+
+	if m.config.DiscordWebhook != "" {
+		m.RegisterNotifier(&discordNotifier{monitor: m, url: m.config.DiscordWebhook})
+	}
+
+	if m.config.PagerDutyRoutingKey != "" {
+		m.RegisterNotifier(&pagerDutyNotifier{monitor: m, routingKey: m.config.PagerDutyRoutingKey})
+	}
+
+	if m.config.WebhookNotifier != nil {
+		m.RegisterNotifier(&webhookNotifier{monitor: m, config: m.config.WebhookNotifier})
+	}
+
+	if m.config.TelegramBotToken != "" && m.config.TelegramChatID != "" {
+		m.RegisterNotifier(&telegramNotifier{
+			monitor: m,
+			token:   m.config.TelegramBotToken,
+			chatID:  m.config.TelegramChatID,
+		})
+	}
+
+	if m.config.EmailAlerts && m.emailConfigured(m.emailGroups()) {
+		m.RegisterNotifier(&emailNotifier{monitor: m})
+	}
+}
+
+// SendNotifications sends the report to every registered notifier
+func (m *UptimeMonitor) SendNotifications(ctx context.Context, report *MonitorReport) {
+	// Runs without failures still reach the notifiers when a status changed,
+	// so channels that track incidents can resolve them
+	changes := detectChanges(m.history.Latest(), report)
+	if report.Downtime == 0 && report.Degraded == 0 && len(changes) == 0 {
+		return
+	}
+
+	if m.config.NotificationsDisabled {
+		m.logger.Info("Notifications suppressed: NOTIFICATIONS_DISABLED is set",
+			zap.Int("notifiers", len(m.notifiers)))
+		return
+	}
+
+	if m.config.NotifyOnChange && len(changes) == 0 {
+		m.logger.Info("Notifications skipped: no status changes since the previous run")
+		return
+	}
+
+	m.notifyState = nil
+	if m.config.NotifyCooldown > 0 {
+		state, err := loadNotifyState(m.config.NotifyStateFile)
+		if err != nil {
+			m.logger.Warn("Starting with empty notification cooldown state", zap.Error(err))
+			state = &notifyState{path: m.config.NotifyStateFile, LastNotified: make(map[string]time.Time)}
+		}
+		m.notifyState = state
+	}
+	alerted := m.AlertResults(report, changes)
+
+	// The cooldown starts only once an alert has gone out; when every
+	// channel failed or skipped the run, the next run tries again
+	delivered := false
+	for _, n := range m.notifiers {
+		sent, err := n.Notify(ctx, report, changes)
+		if err != nil {
+			m.logger.Error("Failed to send notification",
+				zap.String("notifier", n.Name()),
+				zap.Error(err))
+		}
+		delivered = delivered || sent
+	}
+
+	if m.notifyState != nil && delivered && len(alerted) > 0 {
+		domains := make([]string, 0, len(alerted))
+		for _, result := range alerted {
+			domains = append(domains, result.Domain)
+		}
+		m.notifyState.mark(domains, report.Timestamp)
+		if err := m.notifyState.Save(); err != nil {
+			m.logger.Error("Failed to save notification cooldown state", zap.Error(err))
+		}
+	}
+}
+
+// emailNotifier mails alerts to the email recipient groups, in the same
+// formats as the fallback report email
+type emailNotifier struct {
+	monitor *UptimeMonitor
+}
+
+func (n *emailNotifier) Name() string { return "email" }
+
+func (n *emailNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	alerts := n.monitor.AlertResults(report, changes)
+	if len(alerts) == 0 {
+		return false, nil
+	}
+
+	var failedServices []string
+	for _, result := range alerts {
+		failedServices = append(failedServices, fmt.Sprintf("%s (%s)", result.Domain, result.Status))
+	}
+
+	subject := fmt.Sprintf("Uptime Alert - %d service(s) down, %d degraded", report.Downtime, report.Degraded)
+	intro := "Failed services:\n" + strings.Join(failedServices, "\n")
+	if err := n.monitor.sendReportEmail(report, n.monitor.emailGroups(), subject, intro); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// slackNotifier posts alerts to a Slack incoming webhook
+type slackNotifier struct {
+	monitor *UptimeMonitor
+	url     string
+}
+
+func (n *slackNotifier) Name() string { return "slack" }
+
+func (n *slackNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	var errs []error
+	sent := false
+	if alerts := n.monitor.AlertResults(report, changes); len(alerts) > 0 {
+		err := n.alert(ctx, report, alerts)
+		sent = err == nil
+		errs = append(errs, err)
+	}
+	if recovered := n.monitor.recoveredDomains(report, changes); len(recovered) > 0 {
+		errs = append(errs, n.recovery(ctx, report, recovered))
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (n *slackNotifier) alert(ctx context.Context, report *MonitorReport, alerts []HealthCheckResult) error {
+	color := "danger"
+	if report.Downtime == 0 {
+		color = "warning"
+	}
+
+	var failedServices []string
+	for _, result := range alerts {
+		line := fmt.Sprintf("%s (%s)", result.Domain, result.Status)
+		if result.Attempts > 1 {
+			line += fmt.Sprintf(" after %d attempts", result.Attempts)
+		}
+		failedServices = append(failedServices, line)
+	}
+
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("🚨 Uptime Alert - %d service(s) down, %d degraded", report.Downtime, report.Degraded),
+		"attachments": []map[string]interface{}{
+			{
+				"color": color,
+				"fields": []map[string]interface{}{
+					{"title": "Environment", "value": report.Environment, "short": true},
+					{"title": "Uptime", "value": fmt.Sprintf("%.2f%%", report.UptimePercent), "short": true},
+					{"title": "Health Score", "value": fmt.Sprintf("%.1f", report.HealthScore), "short": true},
+					{"title": "Down", "value": fmt.Sprintf("%d", report.Downtime), "short": true},
+					{"title": "Degraded", "value": fmt.Sprintf("%d", report.Degraded), "short": true},
+					{"title": "Failed Services", "value": strings.Join(failedServices, "\n"), "short": false},
+				},
+				"footer": "Uptime Monitor",
+				"ts":     report.Timestamp.Unix(),
+			},
+		},
+	}
+
+	return n.monitor.sendWebhook(ctx, n.url, payload)
+}
+
+func (n *slackNotifier) recovery(ctx context.Context, report *MonitorReport, recovered []recoveredDomain) error {
+	var services []string
+	for _, r := range recovered {
+		services = append(services, fmt.Sprintf("%s (%s)", r.Domain, r.describe()))
+	}
+
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("✅ Recovered - %d service(s) back up", len(recovered)),
+		"attachments": []map[string]interface{}{
+			{
+				"color": "good",
+				"fields": []map[string]interface{}{
+					{"title": "Environment", "value": report.Environment, "short": true},
+					{"title": "Uptime", "value": fmt.Sprintf("%.2f%%", report.UptimePercent), "short": true},
+					{"title": "Recovered Services", "value": strings.Join(services, "\n"), "short": false},
+				},
+				"footer": "Uptime Monitor",
+				"ts":     report.Timestamp.Unix(),
+			},
+		},
+	}
+
+	return n.monitor.sendWebhook(ctx, n.url, payload)
+}
+
+// discordNotifier posts alerts to a Discord webhook
+type discordNotifier struct {
+	monitor *UptimeMonitor
+	url     string
+}
+
+func (n *discordNotifier) Name() string { return "discord" }
+
+func (n *discordNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	var errs []error
+	sent := false
+	if alerts := n.monitor.AlertResults(report, changes); len(alerts) > 0 {
+		err := n.alert(ctx, report, alerts)
+		sent = err == nil
+		errs = append(errs, err)
+	}
+	if recovered := n.monitor.recoveredDomains(report, changes); len(recovered) > 0 {
+		errs = append(errs, n.recovery(ctx, report, recovered))
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (n *discordNotifier) alert(ctx context.Context, report *MonitorReport, alerts []HealthCheckResult) error {
+	var failedServices []string
+	for _, result := range alerts {
+		emoji := "🔴"
+		if result.Status == StatusDegraded {
+			emoji = "🟡"
+		}
+		failedServices = append(failedServices, fmt.Sprintf("%s **%s** - %s", emoji, result.Domain, result.Status))
+	}
+
+	content := fmt.Sprintf("🚨 **Uptime Alert**\n\n"+
+		"**Environment:** %s\n"+
+		"**Uptime:** %.2f%%\n"+
+		"**Health Score:** %.1f\n"+
+		"**Down:** %d | **Degraded:** %d\n\n"+
+		"**Failed Services:**\n%s",
+		report.Environment,
+		report.UptimePercent,
+		report.HealthScore,
+		report.Downtime,
+		report.Degraded,
+		strings.Join(failedServices, "\n"))
+
+	payload := map[string]interface{}{
+		"content":  content,
+		"username": "Uptime Monitor",
+	}
+
+	return n.monitor.sendWebhook(ctx, n.url, payload)
+}
+
+func (n *discordNotifier) recovery(ctx context.Context, report *MonitorReport, recovered []recoveredDomain) error {
+	var services []string
+	for _, r := range recovered {
+		services = append(services, fmt.Sprintf("🟢 **%s** - %s", r.Domain, r.describe()))
+	}
+
+	content := fmt.Sprintf("✅ **Recovered**\n\n"+
+		"**Environment:** %s\n"+
+		"**Uptime:** %.2f%%\n\n"+
+		"**Recovered Services:**\n%s",
+		report.Environment,
+		report.UptimePercent,
+		strings.Join(services, "\n"))
+
+	payload := map[string]interface{}{
+		"content":  content,
+		"username": "Uptime Monitor",
+	}
+
+	return n.monitor.sendWebhook(ctx, n.url, payload)
+}
+
+// telegramAPIBase is the Telegram Bot API endpoint
+const telegramAPIBase = "https://api.telegram.org"
+
+// telegramEscaper escapes the characters MarkdownV2 reserves
+var telegramEscaper = strings.NewReplacer(
+	"_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)",
+	"~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-",
+	"=", "\\=", "|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!",
+	"\\", "\\\\",
+)
+
+// telegramNotifier posts alerts to a Telegram chat through the Bot API
+type telegramNotifier struct {
+	monitor *UptimeMonitor
+	token   string
+	chatID  string
+}
+
+func (n *telegramNotifier) Name() string { return "telegram" }
+
+func (n *telegramNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	var errs []error
+	sent := false
+	if alerts := n.monitor.AlertResults(report, changes); len(alerts) > 0 {
+		err := n.alert(ctx, report, alerts)
+		sent = err == nil
+		errs = append(errs, err)
+	}
+	if recovered := n.monitor.recoveredDomains(report, changes); len(recovered) > 0 {
+		errs = append(errs, n.recovery(ctx, report, recovered))
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (n *telegramNotifier) alert(ctx context.Context, report *MonitorReport, alerts []HealthCheckResult) error {
+	var failedServices []string
+	for _, result := range alerts {
+		emoji := "🔴"
+		if result.Status == StatusDegraded {
+			emoji = "🟡"
+		}
+		failedServices = append(failedServices, fmt.Sprintf("%s *%s* \\- %s",
+			emoji, telegramEscaper.Replace(result.Domain), result.Status))
+	}
+
+	text := fmt.Sprintf("🚨 *Uptime Alert*\n\n"+
+		"*Environment:* %s\n"+
+		"*Uptime:* %s%%\n"+
+		"*Health Score:* %s\n"+
+		"*Down:* %d \\| *Degraded:* %d\n\n"+
+		"*Failed Services:*\n%s",
+		telegramEscaper.Replace(report.Environment),
+		telegramEscaper.Replace(fmt.Sprintf("%.2f", report.UptimePercent)),
+		telegramEscaper.Replace(fmt.Sprintf("%.1f", report.HealthScore)),
+		report.Downtime,
+		report.Degraded,
+		strings.Join(failedServices, "\n"))
+
+	return n.send(ctx, text)
+}
+
+func (n *telegramNotifier) recovery(ctx context.Context, report *MonitorReport, recovered []recoveredDomain) error {
+	var services []string
+	for _, r := range recovered {
+		services = append(services, fmt.Sprintf("🟢 *%s* \\- %s",
+			telegramEscaper.Replace(r.Domain), telegramEscaper.Replace(r.describe())))
+	}
+
+	text := fmt.Sprintf("✅ *Recovered*\n\n"+
+		"*Environment:* %s\n"+
+		"*Uptime:* %s%%\n\n"+
+		"*Recovered Services:*\n%s",
+		telegramEscaper.Replace(report.Environment),
+		telegramEscaper.Replace(fmt.Sprintf("%.2f", report.UptimePercent)),
+		strings.Join(services, "\n"))
+
+	return n.send(ctx, text)
+}
+
+// send posts a MarkdownV2 message to the configured chat
+func (n *telegramNotifier) send(ctx context.Context, text string) error {
+	payload := map[string]interface{}{
+		"chat_id":    n.chatID,
+		"text":       text,
+		"parse_mode": "MarkdownV2",
+	}
+
+	return n.monitor.sendWebhook(ctx, fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIBase, n.token), payload)
+}
@@ -0,0 +1,91 @@
+package uptime
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// stubNotifier returns skip and err from every Notify, reporting a send
+// unless it skipped or failed
+type stubNotifier struct {
+	skip  bool
+	err   error
+	calls int
+}
+
+func (n *stubNotifier) Name() string { return "stub" }
+
+func (n *stubNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	n.calls++
+	return !n.skip && n.err == nil, n.err
+}
+
+func TestCooldownStartsOnlyAfterDelivery(t *testing.T) {
+	t.Setenv("MONITOR_DOMAINS", "example.com")
+	t.Setenv("NOTIFY_COOLDOWN", "15m")
+	t.Setenv("NOTIFY_STATE_FILE", filepath.Join(t.TempDir(), "notify_state.json"))
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor := NewUptimeMonitor(config, zap.NewNop())
+	notifier := &stubNotifier{err: errors.New("channel unavailable")}
+	monitor.RegisterNotifier(notifier)
+
+	report := &MonitorReport{
+		Timestamp: time.Now(),
+		Downtime:  1,
+		Results:   []HealthCheckResult{{Domain: "example.com", Status: StatusDown}},
+	}
+
+	monitor.SendNotifications(context.Background(), report)
+	if _, ok := monitor.notifyState.LastNotified["example.com"]; ok {
+		t.Fatal("cooldown started although every notifier failed")
+	}
+
+	notifier.err = nil
+	monitor.SendNotifications(context.Background(), report)
+	if _, ok := monitor.notifyState.LastNotified["example.com"]; !ok {
+		t.Fatal("cooldown not started after a successful notification")
+	}
+
+	monitor.SendNotifications(context.Background(), report)
+	if notifier.calls != 3 {
+		t.Fatalf("notifier called %d times, want 3", notifier.calls)
+	}
+	if alerts := monitor.AlertResults(report, nil); len(alerts) != 0 {
+		t.Fatalf("domain still alerted on during its cooldown: %v", alerts)
+	}
+}
+
+func TestCooldownIgnoresNotifierThatSkipped(t *testing.T) {
+	t.Setenv("MONITOR_DOMAINS", "example.com")
+	t.Setenv("NOTIFY_COOLDOWN", "15m")
+	t.Setenv("NOTIFY_STATE_FILE", filepath.Join(t.TempDir(), "notify_state.json"))
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor := NewUptimeMonitor(config, zap.NewNop())
+	notifier := &stubNotifier{skip: true}
+	monitor.RegisterNotifier(notifier)
+
+	report := &MonitorReport{
+		Timestamp: time.Now(),
+		Downtime:  1,
+		Results:   []HealthCheckResult{{Domain: "example.com", Status: StatusDown}},
+	}
+
+	monitor.SendNotifications(context.Background(), report)
+	if notifier.calls != 1 {
+		t.Fatalf("notifier called %d times, want 1", notifier.calls)
+	}
+	if _, ok := monitor.notifyState.LastNotified["example.com"]; ok {
+		t.Fatal("cooldown started although the only notifier sent nothing")
+	}
+}
@@ -0,0 +1,119 @@
+package uptime
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyNotifier opens, updates and resolves PagerDuty incidents. Each
+// failing domain has its own incident, so repeated runs update it and it is
+// resolved as soon as that domain stops failing, or is dropped from the
+// config, without touching the incidents of other domains.
+type pagerDutyNotifier struct {
+	monitor    *UptimeMonitor
+	routingKey string
+}
+
+func (n *pagerDutyNotifier) Name() string { return "pagerduty" }
+
+func (n *pagerDutyNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	failing := make(map[string]bool)
+	for _, result := range failedResults(report) {
+		failing[result.Domain] = true
+	}
+
+	// A failed resolve is only logged; it must not keep new failures from
+	// being paged
+	for _, domain := range n.previousFailingDomains() {
+		if failing[domain] {
+			continue
+		}
+		if err := n.send(ctx, map[string]interface{}{
+			"routing_key":  n.routingKey,
+			"event_action": "resolve",
+			"dedup_key":    pagerDutyDedupKey(report.Environment, domain),
+		}); err != nil {
+			n.monitor.logger.Warn("Failed to resolve PagerDuty incident",
+				zap.String("domain", redactURL(domain)),
+				zap.Error(err))
+		}
+	}
+
+	source, _ := os.Hostname()
+	if source == "" {
+		source = "uptime-monitor"
+	}
+
+	sent := false
+	var errs []error
+	for _, result := range failedResults(report) {
+		severity := "warning"
+		if result.Status == StatusDown {
+			severity = "critical"
+		}
+
+		err := n.send(ctx, map[string]interface{}{
+			"routing_key":  n.routingKey,
+			"event_action": "trigger",
+			"dedup_key":    pagerDutyDedupKey(report.Environment, result.Domain),
+			"payload": map[string]interface{}{
+				"summary":   fmt.Sprintf("[%s] %s is %s", report.Environment, redactURL(result.Domain), result.Status),
+				"source":    source,
+				"severity":  severity,
+				"timestamp": report.Timestamp.Format(time.RFC3339),
+				"component": report.Service,
+				"custom_details": map[string]interface{}{
+					"environment":    report.Environment,
+					"status":         result.Status,
+					"error_message":  result.ErrorMessage,
+					"uptime_percent": report.UptimePercent,
+					"health_score":   report.HealthScore,
+				},
+			},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to trigger incident for %s: %w", redactURL(result.Domain), err))
+			continue
+		}
+		sent = true
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (n *pagerDutyNotifier) send(ctx context.Context, event map[string]interface{}) error {
+	return n.monitor.sendWebhook(ctx, pagerDutyEventsURL, event)
+}
+
+// previousFailingDomains returns the sorted domains that were down or
+// degraded in the previous run, including any since removed from the config
+func (n *pagerDutyNotifier) previousFailingDomains() []string {
+	previous := n.monitor.history.Latest()
+	if previous == nil {
+		return nil
+	}
+
+	var domains []string
+	for _, result := range failedResults(previous) {
+		domains = append(domains, result.Domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// pagerDutyDedupKey derives a stable incident key for one domain. The domain
+// is hashed so credentials in a domain entry never reach PagerDuty.
+func pagerDutyDedupKey(environment, domain string) string {
+	sum := sha256.Sum256([]byte(domain))
+	return fmt.Sprintf("uptime-monitor/%s/%s", environment, hex.EncodeToString(sum[:8]))
+}
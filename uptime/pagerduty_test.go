@@ -0,0 +1,95 @@
+package uptime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rewriteTransport sends every request to target, keeping the path
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = rt.target.Scheme
+	req.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestPagerDutyResolvesPerDomainAndTriggersAfterFailedResolve(t *testing.T) {
+	failingKey := pagerDutyDedupKey("production", "a.example.com")
+
+	var mu sync.Mutex
+	events := make(map[string]string) // dedup key -> event action
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			Action   string `json:"event_action"`
+			DedupKey string `json:"dedup_key"`
+		}
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		events[event.DedupKey] = event.Action
+		mu.Unlock()
+		if event.DedupKey == failingKey {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	t.Setenv("MONITOR_DOMAINS", "a.example.com,b.example.com")
+	t.Setenv("PAGERDUTY_ROUTING_KEY", "routing-key")
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewUptimeMonitor(config, zap.NewNop())
+	m.client = &http.Client{Transport: rewriteTransport{target: target}}
+
+	// removed.example.com was failing and has since been dropped from the config
+	m.history.Add(&MonitorReport{
+		Environment: "production",
+		Timestamp:   time.Now().Add(-time.Minute),
+		Results: []HealthCheckResult{
+			{Domain: "a.example.com", Status: StatusDown},
+			{Domain: "b.example.com", Status: StatusUp},
+			{Domain: "removed.example.com", Status: StatusDown},
+		},
+	})
+	m.SendNotifications(context.Background(), &MonitorReport{
+		Environment: "production",
+		Timestamp:   time.Now(),
+		Downtime:    1,
+		Results: []HealthCheckResult{
+			{Domain: "a.example.com", Status: StatusUp},
+			{Domain: "b.example.com", Status: StatusDown},
+		},
+	})
+
+	want := map[string]string{
+		failingKey: "resolve",
+		pagerDutyDedupKey("production", "removed.example.com"): "resolve",
+		pagerDutyDedupKey("production", "b.example.com"):       "trigger",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for key, action := range want {
+		if events[key] != action {
+			t.Errorf("event for %s = %q, want %q", key, events[key], action)
+		}
+	}
+	if len(events) != len(want) {
+		t.Errorf("got %d events, want %d: %v", len(events), len(want), events)
+	}
+}
@@ -0,0 +1,162 @@
+package uptime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// QUEUE_TYPE values. The sink speaks HTTP to a broker's gateway, not the
+// AMQP or Kafka wire protocols, so no broker client library is needed.
+const (
+	QueueRabbitMQHTTP = "rabbitmq-http" // RabbitMQ management HTTP API
+	QueueKafkaREST    = "kafka-rest"    // Confluent-compatible Kafka REST Proxy (v2)
+)
+
+// QueueConfig configures the HTTP-bridge message queue sink
+type QueueConfig struct {
+	Type       string // rabbitmq-http or kafka-rest
+	URL        string // management API or REST proxy base URL; userinfo is sent as basic auth
+	Topic      string // exchange (rabbitmq-http) or topic (kafka-rest)
+	RoutingKey string // rabbitmq-http only
+	VHost      string // rabbitmq-http only, defaults to /
+}
+
+// queueEnvelope wraps the report with metadata about the run that produced it
+type queueEnvelope struct {
+	Type        string         `json:"type"`
+	Host        string         `json:"host,omitempty"`
+	PublishedAt time.Time      `json:"published_at"`
+	Report      *MonitorReport `json:"report"`
+}
+
+// parseQueueConfig reads QUEUE_TYPE, QUEUE_URL, QUEUE_TOPIC, QUEUE_ROUTING_KEY
+// and QUEUE_VHOST. The sink is disabled when QUEUE_URL is unset.
+func parseQueueConfig() (QueueConfig, error) {
+	qc := QueueConfig{
+		Type:       strings.ToLower(getEnvOrDefault("QUEUE_TYPE", QueueRabbitMQHTTP)),
+		URL:        strings.TrimRight(getEnv("QUEUE_URL"), "/"),
+		Topic:      getEnv("QUEUE_TOPIC"),
+		RoutingKey: getEnv("QUEUE_ROUTING_KEY"),
+		VHost:      getEnvOrDefault("QUEUE_VHOST", "/"),
+	}
+	if qc.URL == "" {
+		return qc, nil
+	}
+
+	if qc.Type != QueueRabbitMQHTTP && qc.Type != QueueKafkaREST {
+		return qc, fmt.Errorf("QUEUE_TYPE must be rabbitmq-http or kafka-rest, got %q", qc.Type)
+	}
+	if qc.Topic == "" {
+		return qc, fmt.Errorf("QUEUE_TOPIC must be set when QUEUE_URL is set")
+	}
+	if _, err := url.Parse(qc.URL); err != nil {
+		return qc, fmt.Errorf("invalid QUEUE_URL: %w", err)
+	}
+	return qc, nil
+}
+
+// PublishToQueue publishes the report to the configured message queue through
+// its HTTP gateway. The shared HTTP client keeps connections to the gateway
+// alive across daemon runs.
+func (m *UptimeMonitor) PublishToQueue(ctx context.Context, report *MonitorReport) error {
+	qc := m.config.Queue
+	if qc.URL == "" {
+		return nil
+	}
+
+	host, _ := os.Hostname()
+	message, err := json.Marshal(queueEnvelope{
+		Type:        "uptime_report",
+		Host:        host,
+		PublishedAt: time.Now().UTC(),
+		Report:      report,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue message: %w", err)
+	}
+
+	endpoint, body, contentType, err := queueRequest(qc, message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create queue request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", m.config.UserAgent)
+	if u, err := url.Parse(qc.URL); err == nil && u.User != nil {
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to queue: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("queue publish failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	// RabbitMQ answers 200 even when no queue is bound to the exchange
+	if qc.Type == QueueRabbitMQHTTP {
+		var result struct {
+			Routed bool `json:"routed"`
+		}
+		if err := json.Unmarshal(respBody, &result); err == nil && !result.Routed {
+			return fmt.Errorf("queue publish was not routed to any queue (exchange %s)", qc.Topic)
+		}
+	}
+
+	m.logger.Info("Report published to queue",
+		zap.String("type", qc.Type),
+		zap.String("topic", qc.Topic))
+	return nil
+}
+
+// queueRequest builds the gateway endpoint and request body for a message
+func queueRequest(qc QueueConfig, message []byte) (string, []byte, string, error) {
+	base, err := url.Parse(qc.URL)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("invalid QUEUE_URL: %w", err)
+	}
+	base.User = nil
+
+	switch qc.Type {
+	case QueueKafkaREST:
+		body, err := json.Marshal(map[string]interface{}{
+			"records": []map[string]json.RawMessage{{"value": message}},
+		})
+		if err != nil {
+			return "", nil, "", fmt.Errorf("failed to marshal queue message: %w", err)
+		}
+		endpoint := base.String() + "/topics/" + url.PathEscape(qc.Topic)
+		return endpoint, body, "application/vnd.kafka.json.v2+json", nil
+	default:
+		body, err := json.Marshal(map[string]interface{}{
+			"properties":       map[string]interface{}{"content_type": "application/json", "delivery_mode": 2},
+			"routing_key":      qc.RoutingKey,
+			"payload":          string(message),
+			"payload_encoding": "string",
+		})
+		if err != nil {
+			return "", nil, "", fmt.Errorf("failed to marshal queue message: %w", err)
+		}
+		endpoint := fmt.Sprintf("%s/api/exchanges/%s/%s/publish", base.String(), url.PathEscape(qc.VHost), url.PathEscape(qc.Topic))
+		return endpoint, body, "application/json", nil
+	}
+}
@@ -0,0 +1,53 @@
+package uptime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseQueueConfigRejectsUnknownType(t *testing.T) {
+	t.Setenv("QUEUE_URL", "http://rabbitmq:15672")
+	t.Setenv("QUEUE_TOPIC", "uptime")
+	t.Setenv("QUEUE_TYPE", "amqp")
+
+	if _, err := parseQueueConfig(); err == nil {
+		t.Error("parseQueueConfig() accepted QUEUE_TYPE=amqp; the sink only speaks HTTP")
+	}
+}
+
+func TestPublishToQueueKafkaREST(t *testing.T) {
+	var records struct {
+		Records []struct {
+			Value queueEnvelope `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/uptime" {
+			t.Errorf("path = %q, want /topics/uptime", r.URL.Path)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	m := &UptimeMonitor{
+		config: &MonitorConfig{Queue: QueueConfig{Type: QueueKafkaREST, URL: server.URL, Topic: "uptime"}},
+		client: server.Client(),
+		logger: zap.NewNop(),
+	}
+	if err := m.PublishToQueue(context.Background(), &MonitorReport{Service: "Uptime Monitor"}); err != nil {
+		t.Fatalf("PublishToQueue() error: %v", err)
+	}
+	if len(records.Records) != 1 || records.Records[0].Value.Report.Service != "Uptime Monitor" {
+		t.Errorf("records = %+v, want one record carrying the report", records.Records)
+	}
+}
@@ -0,0 +1,46 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// RETRY_RATE_LIMIT modes: how retry attempts are rate limited
+const (
+	RetryRateLimitSeparate = "separate" // retries draw from their own bucket
+	RetryRateLimitShared   = "shared"   // retries share the check bucket
+	RetryRateLimitNone     = "none"     // retries are not rate limited
+)
+
+// newRetryRateLimiter builds the limiter for retry attempts. It returns the
+// check limiter for shared mode and nil when retries are exempt.
+func newRetryRateLimiter(mode string, checks *rate.Limiter) (*rate.Limiter, error) {
+	switch strings.ToLower(mode) {
+	case RetryRateLimitSeparate:
+		return rate.NewLimiter(rate.Limit(RequestsPerSecond), BurstSize), nil
+	case RetryRateLimitShared:
+		return checks, nil
+	case RetryRateLimitNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("RETRY_RATE_LIMIT must be separate, shared or none, got %q", mode)
+	}
+}
+
+// waitRateLimit takes a token for a request attempt. The first attempt uses
+// the check limiter and retries use RetryRateLimiter, so a wave of retries
+// during an outage can't hold back first attempts at other domains. Each
+// attempt is charged exactly once.
+func (m *UptimeMonitor) waitRateLimit(ctx context.Context, attempt int) error {
+	limiter := m.config.RateLimiter
+	if attempt > 0 {
+		limiter = m.config.RetryRateLimiter
+	}
+	if limiter == nil {
+		return ctx.Err()
+	}
+	return limiter.Wait(ctx)
+}
@@ -0,0 +1,65 @@
+package uptime
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestWaitRateLimitChargesOneTokenPerAttempt(t *testing.T) {
+	tests := []struct {
+		mode        string
+		attempts    []int
+		wantChecks  float64
+		wantRetries float64 // ignored when retries are exempt
+	}{
+		{mode: RetryRateLimitSeparate, attempts: []int{0, 1, 2}, wantChecks: 4, wantRetries: 3},
+		{mode: RetryRateLimitShared, attempts: []int{0, 1, 2}, wantChecks: 2, wantRetries: 2},
+		{mode: RetryRateLimitNone, attempts: []int{0, 1, 2}, wantChecks: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			// A refill this slow keeps the counts exact for the test's duration
+			checks := rate.NewLimiter(rate.Every(time.Hour), 5)
+			retries, err := newRetryRateLimiter(tt.mode, checks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if retries != nil && retries != checks {
+				retries = rate.NewLimiter(rate.Every(time.Hour), 5)
+			}
+			m := &UptimeMonitor{config: &MonitorConfig{RateLimiter: checks, RetryRateLimiter: retries}}
+
+			for _, attempt := range tt.attempts {
+				if err := m.waitRateLimit(context.Background(), attempt); err != nil {
+					t.Fatalf("waitRateLimit(%d) error: %v", attempt, err)
+				}
+			}
+
+			if got := checks.Tokens(); math.Abs(got-tt.wantChecks) > 0.01 {
+				t.Errorf("check bucket has %.2f tokens, want %v", got, tt.wantChecks)
+			}
+			if retries == nil {
+				return
+			}
+			if got := retries.Tokens(); math.Abs(got-tt.wantRetries) > 0.01 {
+				t.Errorf("retry bucket has %.2f tokens, want %v", got, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestRetryRateLimitDefaultsToShared(t *testing.T) {
+	t.Setenv("MONITOR_DOMAINS", "example.com")
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.RetryRateLimiter != config.RateLimiter {
+		t.Error("retries don't share the check limiter by default")
+	}
+}
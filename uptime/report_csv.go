@@ -0,0 +1,110 @@
+package uptime
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ReportFormatJSON     = "json"
+	ReportFormatCSV      = "csv"
+	ReportFormatMarkdown = "markdown"
+	ReportFormatBoth     = "both" // json and csv
+)
+
+// parseReportFormats reads REPORT_FORMAT, a comma-separated list of formats
+// to save each run in
+func parseReportFormats(raw string) ([]string, error) {
+	var formats []string
+	for _, format := range strings.Split(strings.ToLower(raw), ",") {
+		switch format = strings.TrimSpace(format); format {
+		case ReportFormatJSON, ReportFormatCSV, ReportFormatMarkdown:
+			formats = append(formats, format)
+		case ReportFormatBoth:
+			formats = append(formats, ReportFormatJSON, ReportFormatCSV)
+		case "":
+		default:
+			return nil, fmt.Errorf("REPORT_FORMAT entries must be json, csv, markdown or both, got %q", format)
+		}
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("REPORT_FORMAT names no formats")
+	}
+	return formats, nil
+}
+
+// savesReportAs reports whether REPORT_FORMAT includes format
+func (c *MonitorConfig) savesReportAs(format string) bool {
+	return slices.Contains(c.ReportFormats, format)
+}
+
+// csvHeader is the header row of CSV reports
+var csvHeader = []string{"domain", "status", "status_code", "latency_ms", "ssl_days_left", "checked_at", "failure_reason"}
+
+// SaveReportCSV writes one row per result to a CSV file named like the JSON
+// report, with a .csv extension
+func (m *UptimeMonitor) SaveReportCSV(report *MonitorReport) (string, error) {
+	filename, err := m.reportPath(report, "csv")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	data, err := encodeReportCSV(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode CSV: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write CSV file: %w", err)
+	}
+
+	m.logger.Info("CSV report saved", zap.String("file", filename))
+	return filename, nil
+}
+
+// encodeReportCSV renders the results as CSV with a header row. The SSL
+// column is left empty for checks that didn't use TLS.
+func encodeReportCSV(report *MonitorReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, r := range report.Results {
+		sslDays := ""
+		if r.IsSSL && r.SSLExpiry != "" {
+			sslDays = strconv.Itoa(r.SSLDaysLeft)
+		}
+		row := []string{
+			r.Domain,
+			r.Status,
+			strconv.Itoa(r.StatusCode),
+			strconv.FormatInt(r.ResponseTime, 10),
+			sslDays,
+			r.CheckedAt,
+			r.FailureReason,
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,154 @@
+package uptime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// markdownCellEscaper keeps cell text from breaking GFM table rows
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "")
+
+// statusEmoji marks a status in Markdown tables
+func statusEmoji(status string) string {
+	switch status {
+	case StatusUp:
+		return "✅"
+	case StatusDown:
+		return "❌"
+	case StatusDegraded:
+		return "⚠️"
+	case StatusBlocked:
+		return "🚫"
+	case StatusMaintenance:
+		return "🔧"
+	default:
+		return "❔"
+	}
+}
+
+// BuildMarkdownReport renders the report as GitHub-flavoured Markdown for PR
+// comments and wikis. It follows the layout of BuildHTMLReport: summary,
+// changes since the last run, failing checks, then every result.
+func BuildMarkdownReport(report *MonitorReport) string {
+	var b strings.Builder
+
+	title := "Uptime Report"
+	if report.Environment != "" {
+		title += " — " + report.Environment
+	}
+	fmt.Fprintf(&b, "# 📡 %s\n\n", title)
+	fmt.Fprintf(&b, "Generated on %s\n\n", report.Timestamp.Format(time.RFC1123))
+
+	b.WriteString("## Summary\n\n")
+	b.WriteString("| Metric | Value |\n|--------|-------|\n")
+	fmt.Fprintf(&b, "| Total Checks | %d |\n", report.TotalChecks)
+	fmt.Fprintf(&b, "| Uptime | %d |\n", report.Uptime)
+	fmt.Fprintf(&b, "| Downtime | %d |\n", report.Downtime)
+	fmt.Fprintf(&b, "| Degraded | %d |\n", report.Degraded)
+	if report.Maintenance > 0 {
+		fmt.Fprintf(&b, "| In Maintenance | %d |\n", report.Maintenance)
+	}
+	fmt.Fprintf(&b, "| Uptime %% | %.2f%% |\n", report.UptimePercent)
+	fmt.Fprintf(&b, "| Avg Latency | %.2f ms |\n", report.AverageLatency)
+	fmt.Fprintf(&b, "| Health Score | %.1f |\n", report.HealthScore)
+
+	if report.ChangesSince != nil {
+		b.WriteString("\n## Changes Since Last Run\n\n")
+		fmt.Fprintf(&b, "Compared with the run at %s.\n\n", report.ChangesSince.Format(time.RFC1123))
+
+		var changed []DomainDiff
+		for _, d := range report.Changes {
+			if d.StatusChanged() {
+				changed = append(changed, d)
+			}
+		}
+		if len(changed) == 0 {
+			b.WriteString("No status changes.\n")
+		} else {
+			b.WriteString("| Domain | Status | Latency Change |\n|--------|--------|----------------|\n")
+			for _, d := range changed {
+				delta := "-"
+				if d.LatencyDelta != nil {
+					delta = fmt.Sprintf("%+d ms", *d.LatencyDelta)
+				}
+				fmt.Fprintf(&b, "| %s | %s → %s | %s |\n",
+					markdownCellEscaper.Replace(d.Domain),
+					markdownDiffStatus(d.PreviousStatus, "new"),
+					markdownDiffStatus(d.Status, "removed"),
+					delta)
+			}
+		}
+	}
+
+	var failing []HealthCheckResult
+	for _, r := range report.Results {
+		if r.Status != StatusUp && r.Status != StatusMaintenance {
+			failing = append(failing, r)
+		}
+	}
+	if len(failing) > 0 {
+		sort.SliceStable(failing, func(i, j int) bool {
+			return statusRank(failing[i].Status) < statusRank(failing[j].Status)
+		})
+		fmt.Fprintf(&b, "\n## Needs Attention (%d)\n\n", len(failing))
+		writeMarkdownResults(&b, failing)
+	}
+
+	b.WriteString("\n## Detailed Results\n\n")
+	writeMarkdownResults(&b, report.Results)
+
+	return b.String()
+}
+
+// writeMarkdownResults writes a results table with the HTML report's columns
+func writeMarkdownResults(b *strings.Builder, results []HealthCheckResult) {
+	b.WriteString("| Domain | Status | Code | Latency | SSL Expiry | Checked At |\n")
+	b.WriteString("|--------|--------|------|---------|------------|------------|\n")
+	for _, r := range results {
+		sslExpiry := r.SSLExpiry
+		if sslExpiry == "" {
+			sslExpiry = "-"
+		}
+		fmt.Fprintf(b, "| %s | %s %s | %d | %d ms | %s | %s |\n",
+			markdownCellEscaper.Replace(r.Domain),
+			statusEmoji(r.Status), strings.ToUpper(r.Status),
+			r.StatusCode, r.ResponseTime,
+			markdownCellEscaper.Replace(sslExpiry),
+			markdownCellEscaper.Replace(r.CheckedAt))
+	}
+}
+
+// markdownDiffStatus renders a status in the changes table, using missing
+// when the domain was absent from that run
+func markdownDiffStatus(status, missing string) string {
+	if status == "" {
+		return "_" + missing + "_"
+	}
+	return statusEmoji(status) + " " + strings.ToUpper(status)
+}
+
+// SaveReportMarkdown writes BuildMarkdownReport to a file named like the JSON
+// report, with a .md extension
+func (m *UptimeMonitor) SaveReportMarkdown(report *MonitorReport) (string, error) {
+	filename, err := m.reportPath(report, "md")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(filename, []byte(BuildMarkdownReport(report)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write Markdown file: %w", err)
+	}
+
+	m.logger.Info("Markdown report saved", zap.String("file", filename))
+	return filename, nil
+}
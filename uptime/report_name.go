@@ -0,0 +1,203 @@
+package uptime
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultReportFilenameTemplate reproduces the uptime_report_{timestamp}.json naming
+const DefaultReportFilenameTemplate = `uptime_report_{{.Timestamp.Format "20060102_150405"}}.json`
+
+// reportService is the Service every report is generated with
+const reportService = "Uptime Monitor"
+
+// reportGlobTimes are rendered through the filename template to find which
+// parts of a report's name change from run to run. They differ in every
+// field and digit so no part of a timestamp is mistaken for fixed text.
+var reportGlobTimes = []time.Time{
+	time.Date(1999, 12, 31, 23, 59, 58, 0, time.UTC),
+	time.Date(2088, 1, 2, 1, 3, 4, 0, time.UTC),
+	time.Date(2044, 6, 15, 12, 30, 30, 0, time.UTC),
+}
+
+// reportNameData is what REPORT_FILENAME_TEMPLATE is executed with
+type reportNameData struct {
+	Timestamp   time.Time
+	Environment string
+	Service     string
+}
+
+// renderReportFilename executes the filename template and returns the path
+// relative to OutputDir. The result must stay inside OutputDir.
+func renderReportFilename(text string, data reportNameData) (string, error) {
+	tmpl, err := template.New("report_filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid REPORT_FILENAME_TEMPLATE: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render REPORT_FILENAME_TEMPLATE: %w", err)
+	}
+
+	name := filepath.Clean(strings.TrimSpace(buf.String()))
+	if name == "." || filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("REPORT_FILENAME_TEMPLATE must render a path inside OUTPUT_DIR, got %q", buf.String())
+	}
+	return name, nil
+}
+
+// validateReportFilenameTemplate renders the template with sample data so a
+// bad template fails at startup rather than when the first report is saved,
+// and checks that saved reports can be found again
+func validateReportFilenameTemplate(text, environment string) error {
+	_, err := reportGlobs(text, environment)
+	return err
+}
+
+// reportGlobs returns the patterns, relative to OutputDir, matching the JSON
+// reports saved under the filename template, plain and gzipped. Parts of the
+// name that change with the timestamp become *, so retention and the
+// previous-run lookup follow custom templates. The file name itself must
+// keep some fixed text, or the patterns would match every JSON file in
+// OutputDir, the history cache included.
+func reportGlobs(text, environment string) ([]string, error) {
+	var renders [][]string
+	for _, ts := range reportGlobTimes {
+		name, err := renderReportFilename(text, reportNameData{
+			Timestamp:   ts,
+			Environment: environment,
+			Service:     reportService,
+		})
+		if err != nil {
+			return nil, err
+		}
+		stem := strings.TrimSuffix(filepath.ToSlash(name), ".json")
+		renders = append(renders, strings.Split(stem, "/"))
+	}
+
+	parts := make([]string, len(renders[0]))
+	for i := range parts {
+		variants := make([]string, 0, len(renders))
+		for _, render := range renders {
+			if len(render) != len(parts) {
+				return nil, fmt.Errorf("REPORT_FILENAME_TEMPLATE must render the same number of directories for every timestamp")
+			}
+			variants = append(variants, render[i])
+		}
+		parts[i] = variantGlob(variants)
+	}
+
+	if strings.Trim(parts[len(parts)-1], "*") == "" {
+		return nil, fmt.Errorf("REPORT_FILENAME_TEMPLATE must keep fixed text in the file name (e.g. uptime_report_), so saved reports can be told apart from other files in OUTPUT_DIR")
+	}
+
+	stem := strings.Join(parts, "/")
+	return []string{filepath.FromSlash(stem + ".json"), filepath.FromSlash(stem + ".json.gz")}, nil
+}
+
+// variantGlob keeps the prefix and suffix the variants share and replaces
+// the rest with *
+func variantGlob(variants []string) string {
+	first := variants[0]
+	if allEqual(variants) {
+		return globEscape(first)
+	}
+
+	prefix, suffix, shortest := len(first), len(first), len(first)
+	for _, v := range variants[1:] {
+		prefix = min(prefix, commonPrefixLen(first, v))
+		suffix = min(suffix, commonSuffixLen(first, v))
+		shortest = min(shortest, len(v))
+	}
+	// Don't let the prefix and suffix overlap in the shortest variant
+	suffix = min(suffix, shortest-prefix)
+	return globEscape(first[:prefix]) + "*" + globEscape(first[len(first)-suffix:])
+}
+
+// allEqual reports whether every value matches the first
+func allEqual(values []string) bool {
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func commonSuffixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// globEscape quotes the characters filepath.Match treats as special
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(s)
+}
+
+// savedReports lists the JSON reports in OutputDir written under the current
+// REPORT_FILENAME_TEMPLATE, oldest first. Names need not sort by time, so
+// they are ordered by modification time.
+func (m *UptimeMonitor) savedReports() ([]string, error) {
+	patterns, err := reportGlobs(m.config.ReportFilenameTemplate, m.config.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(m.config.OutputDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		ti, tj := modTimes[paths[i]], modTimes[paths[j]]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return paths[i] < paths[j]
+	})
+	return paths, nil
+}
+
+// reportPath returns where a report should be saved, with ext ("json" or
+// "csv") replacing any .json suffix the template renders. The name comes
+// from the report's own timestamp, so every format of one report shares it.
+func (m *UptimeMonitor) reportPath(report *MonitorReport, ext string) (string, error) {
+	name, err := renderReportFilename(m.config.ReportFilenameTemplate, reportNameData{
+		Timestamp:   report.Timestamp,
+		Environment: report.Environment,
+		Service:     report.Service,
+	})
+	if err != nil {
+		return "", err
+	}
+	name = strings.TrimSuffix(name, ".json") + "." + ext
+	return filepath.Join(m.config.OutputDir, name), nil
+}
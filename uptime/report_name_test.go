@@ -0,0 +1,119 @@
+package uptime
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestReportGlobs(t *testing.T) {
+	tests := []struct {
+		template string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			template: DefaultReportFilenameTemplate,
+			want:     []string{"uptime_report_*.json", "uptime_report_*.json.gz"},
+		},
+		{
+			template: `{{.Timestamp.Format "2006/01/02"}}/{{.Environment}}/run.json`,
+			want:     []string{"*/*/*/staging/run.json", "*/*/*/staging/run.json.gz"},
+		},
+		{
+			template: `{{.Environment}}-{{.Timestamp.Format "Jan-2"}}.json`,
+			want:     []string{"staging-*.json", "staging-*.json.gz"},
+		},
+		{
+			template: `{{.Timestamp.Format "20060102"}}.json`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := reportGlobs(tt.template, "staging")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("reportGlobs(%q) = %v, want error", tt.template, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("reportGlobs(%q) error: %v", tt.template, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("reportGlobs(%q) = %v, want %v", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestSavedReportsFollowsCustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	m := &UptimeMonitor{
+		config: &MonitorConfig{
+			OutputDir:              dir,
+			Environment:            "production",
+			ReportFilenameTemplate: `{{.Timestamp.Format "2006/01/02"}}/run.json`,
+		},
+		logger: zap.NewNop(),
+	}
+
+	base := time.Date(2025, 11, 9, 10, 0, 0, 0, time.UTC)
+	var want []string
+	for i := 0; i < 3; i++ {
+		report := &MonitorReport{Timestamp: base.AddDate(0, 0, i), Environment: "production"}
+		path, err := m.reportPath(report, "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(`{}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, path)
+	}
+	// Files the template doesn't produce are left alone
+	if err := os.WriteFile(filepath.Join(dir, "history.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := m.savedReports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("savedReports() = %v, want %v", got, want)
+	}
+}
+
+func TestReportPathUsesReportTimestamp(t *testing.T) {
+	m := &UptimeMonitor{config: &MonitorConfig{OutputDir: "out", ReportFilenameTemplate: DefaultReportFilenameTemplate}}
+	report := &MonitorReport{Timestamp: time.Date(2025, 11, 9, 10, 30, 0, 0, time.UTC)}
+
+	jsonPath, err := m.reportPath(report, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvPath, err := m.reportPath(report, "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join("out", "uptime_report_20251109_103000.json"); jsonPath != want {
+		t.Errorf("json path = %q, want %q", jsonPath, want)
+	}
+	if want := filepath.Join("out", "uptime_report_20251109_103000.csv"); csvPath != want {
+		t.Errorf("csv path = %q, want %q", csvPath, want)
+	}
+}
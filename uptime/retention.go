@@ -0,0 +1,55 @@
+package uptime
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// pruneReports applies REPORT_RETENTION_DAYS and REPORT_MAX_FILES to the JSON
+// reports in OutputDir. Only files named by REPORT_FILENAME_TEMPLATE are
+// considered, and keep, the report just written, is never deleted.
+func (m *UptimeMonitor) pruneReports(keep string) {
+	if m.config.ReportRetentionDays <= 0 && m.config.ReportMaxFiles <= 0 {
+		return
+	}
+
+	paths, err := m.savedReports()
+	if err != nil {
+		m.logger.Warn("Failed to list reports for retention", zap.Error(err))
+		return
+	}
+
+	keepClean := filepath.Clean(keep)
+	var expired []string
+	cutoff := time.Now().AddDate(0, 0, -m.config.ReportRetentionDays)
+	excess := 0
+	if m.config.ReportMaxFiles > 0 {
+		excess = len(paths) - m.config.ReportMaxFiles
+	}
+
+	for i, path := range paths {
+		if filepath.Clean(path) == keepClean {
+			continue
+		}
+		if i < excess {
+			expired = append(expired, path)
+			continue
+		}
+		if m.config.ReportRetentionDays > 0 {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				expired = append(expired, path)
+			}
+		}
+	}
+
+	for _, path := range expired {
+		if err := os.Remove(path); err != nil {
+			m.logger.Warn("Failed to delete old report", zap.String("file", path), zap.Error(err))
+			continue
+		}
+		m.logger.Info("Deleted old report", zap.String("file", path))
+	}
+}
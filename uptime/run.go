@@ -0,0 +1,184 @@
+package uptime
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RunCycle performs a full run: it checks every domain, then saves, submits,
+// publishes and notifies the report, and records it in the history cache. It
+// returns the report and the process exit code for it under EXIT_POLICY.
+func (m *UptimeMonitor) RunCycle(parent context.Context) (*MonitorReport, int, error) {
+	subject := "Failed trying to submit the report to API"
+
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
+	defer cancel()
+
+	cycleStart := time.Now()
+
+	report, err := m.RunCheck(ctx)
+	if err != nil {
+		return nil, 1, err
+	}
+	checkDuration := time.Since(cycleStart)
+
+	m.AttachChanges(report)
+
+	phaseStart := time.Now()
+	if m.config.savesReportAs(ReportFormatJSON) {
+		if _, err := m.SaveReport(report); err != nil {
+			m.logger.Error("Failed to save report", zap.Error(err))
+		}
+	}
+	if m.config.savesReportAs(ReportFormatCSV) {
+		if _, err := m.SaveReportCSV(report); err != nil {
+			m.logger.Error("Failed to save CSV report", zap.Error(err))
+		}
+	}
+	if m.config.savesReportAs(ReportFormatMarkdown) {
+		if _, err := m.SaveReportMarkdown(report); err != nil {
+			m.logger.Error("Failed to save Markdown report", zap.Error(err))
+		}
+	}
+	if getEnv("SUPABASE_URL") != "" && getEnv("SUPABASE_KEY") != "" {
+		if err := storeResults(report); err != nil {
+			m.logger.Error("Failed to store results in Supabase", zap.Error(err))
+		}
+	}
+	if err := m.ExportMetrics(report); err != nil {
+		m.logger.Error("Failed to export metrics", zap.Error(err))
+	}
+	saveDuration := time.Since(phaseStart)
+
+	phaseStart = time.Now()
+	if err := m.SubmitToAPI(ctx, report); err != nil {
+		m.logger.Error("Failed to submit report to API", zap.Error(err))
+		m.SendEmailOnFailure(report, &subject)
+	}
+	// The report was saved before submission so the payload could name the
+	// file; record which targets accepted it now that that's known
+	if len(report.SubmittedTo) > 0 {
+		if err := m.updateSavedReport(report); err != nil {
+			m.logger.Error("Failed to record API submission in saved report", zap.Error(err))
+		}
+	}
+	submitDuration := time.Since(phaseStart)
+
+	phaseStart = time.Now()
+	if err := m.PublishToQueue(ctx, report); err != nil {
+		m.logger.Error("Failed to publish report to queue", zap.Error(err))
+	}
+	publishDuration := time.Since(phaseStart)
+
+	phaseStart = time.Now()
+	m.SendNotifications(ctx, report)
+	notifyDuration := time.Since(phaseStart)
+
+	phaseStart = time.Now()
+	if err := m.RunPostCommand(ctx, report); err != nil {
+		m.logger.Error("Post-run command failed", zap.Error(err))
+	}
+	hookDuration := time.Since(phaseStart)
+
+	totalDuration := time.Since(cycleStart)
+	m.logger.Info("Run phase timings",
+		zap.Duration("checks", checkDuration),
+		zap.Duration("save", saveDuration),
+		zap.Duration("submit", submitDuration),
+		zap.Duration("publish", publishDuration),
+		zap.Duration("notify", notifyDuration),
+		zap.Duration("hook", hookDuration),
+		zap.Duration("total", totalDuration),
+	)
+
+	if interval := m.config.Interval; interval > 0 && totalDuration > interval {
+		m.logger.Warn("Run took longer than the daemon interval",
+			zap.Duration("total", totalDuration),
+			zap.Duration("interval", interval))
+	}
+
+	m.history.Add(report)
+
+	exitCode := m.config.ExitCode(report)
+
+	m.logger.Info("Monitoring completed successfully",
+		zap.Int("exit_code", exitCode),
+		zap.Float64("uptime_percent", report.UptimePercent),
+		zap.Int("total_checks", report.TotalChecks),
+		zap.Int("degraded", report.Degraded),
+	)
+
+	return report, exitCode, nil
+}
+
+// RunDaemon runs a cycle every MONITOR_INTERVAL until ctx is cancelled,
+// serving metrics if METRICS_ADDR is set and saving the history cache on
+// shutdown
+func (m *UptimeMonitor) RunDaemon(ctx context.Context) {
+	m.logger.Info("Starting daemon mode", zap.Duration("interval", m.config.Interval))
+
+	if m.config.MetricsAddr != "" {
+		go m.serveMetrics(ctx)
+	}
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, _, err := m.RunCycle(ctx); err != nil {
+			m.logger.Error("Monitoring failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Shutting down daemon")
+			if err := m.SaveHistory(); err != nil {
+				m.logger.Error("Failed to persist history cache", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replay sends a previously saved report to the API and notification
+// channels without running any checks
+func (m *UptimeMonitor) Replay(ctx context.Context, path string) error {
+	report, err := LoadReport(path)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	m.logger.Info("Replaying report",
+		zap.String("file", path),
+		zap.Time("report_timestamp", report.Timestamp))
+
+	report.ReportFile = path
+	report.SubmittedTo = nil
+
+	submitErr := m.SubmitToAPI(ctx, report)
+	m.SendNotifications(ctx, report)
+
+	return submitErr
+}
+
+// LoadHistory restores the history cache from HISTORY_FILE
+func (m *UptimeMonitor) LoadHistory() error {
+	return m.history.Load()
+}
+
+// SaveHistory persists the history cache to HISTORY_FILE
+func (m *UptimeMonitor) SaveHistory() error {
+	return m.history.Save()
+}
+
+// LatestReport returns the most recent report in the history cache, or nil
+// before the first run
+func (m *UptimeMonitor) LatestReport() *MonitorReport {
+	return m.history.Latest()
+}
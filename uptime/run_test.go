@@ -0,0 +1,66 @@
+package uptime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestRunCheckCancelledMidRun(t *testing.T) {
+	slowStarted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(slowStarted)
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// One slot, so the later domains wait behind the slow one
+	t.Setenv("MONITOR_DOMAINS", server.URL+"/slow,"+server.URL+"/a,"+server.URL+"/b")
+	t.Setenv("MONITOR_CONCURRENT", "1")
+	t.Setenv("RETRY_MAX", "0")
+
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewUptimeMonitor(config, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-slowStarted
+		cancel()
+	}()
+
+	report, err := m.RunCheck(ctx)
+	if err != nil {
+		t.Fatalf("RunCheck() error: %v", err)
+	}
+
+	if len(report.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(report.Results))
+	}
+	for _, result := range report.Results {
+		if result.Status != StatusDown {
+			t.Errorf("%s: status = %q, want %q", result.Domain, result.Status, StatusDown)
+		}
+	}
+
+	wantUnreached := []string{server.URL + "/a", server.URL + "/b"}
+	if strings.Join(report.Unreached, ",") != strings.Join(wantUnreached, ",") {
+		t.Errorf("Unreached = %v, want %v", report.Unreached, wantUnreached)
+	}
+	for _, result := range report.Results[1:] {
+		if !strings.HasPrefix(result.ErrorMessage, "Check not started") {
+			t.Errorf("%s: error = %q, want a not-started result", result.Domain, result.ErrorMessage)
+		}
+	}
+}
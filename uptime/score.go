@@ -0,0 +1,123 @@
+package uptime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ScoreWeights sets how much each signal contributes to the run's health score
+type ScoreWeights struct {
+	Uptime     float64
+	Latency    float64
+	SSL        float64
+	Assertions float64
+}
+
+// DefaultScoreWeights returns the weights used when HEALTH_SCORE_WEIGHTS is unset
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{Uptime: 0.5, Latency: 0.2, SSL: 0.15, Assertions: 0.15}
+}
+
+// parseScoreWeights decodes HEALTH_SCORE_WEIGHTS, e.g. "uptime=0.6,latency=0.4".
+// Signals left out keep their default weight.
+func parseScoreWeights(raw string) (ScoreWeights, error) {
+	weights := DefaultScoreWeights()
+	if raw == "" {
+		return weights, nil
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return weights, fmt.Errorf("invalid HEALTH_SCORE_WEIGHTS entry %q", pair)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || w < 0 {
+			return weights, fmt.Errorf("invalid HEALTH_SCORE_WEIGHTS weight for %s: %q", key, value)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "uptime":
+			weights.Uptime = w
+		case "latency":
+			weights.Latency = w
+		case "ssl":
+			weights.SSL = w
+		case "assertions":
+			weights.Assertions = w
+		default:
+			return weights, fmt.Errorf("unknown HEALTH_SCORE_WEIGHTS signal %q", key)
+		}
+	}
+
+	return weights, nil
+}
+
+// healthScore combines uptime, latency against each domain's thresholds, SSL
+// headroom and transaction step pass rate into a 0-100 score. Signals with no
+// samples in this run (no HTTPS domains, no steps) are left out and the
+// remaining weights are rescaled.
+func (m *UptimeMonitor) healthScore(results []HealthCheckResult, uptimePercent float64) float64 {
+	var latencySum, sslSum float64
+	var latencyCount, sslCount, steps, passed int
+
+	for _, result := range results {
+		if result.Status == StatusBlocked || result.Status == StatusMaintenance {
+			continue
+		}
+
+		if result.Status != StatusDown {
+			latencySum += latencyScore(result.ResponseTime, m.domainSettings(result.Domain))
+			latencyCount++
+		}
+
+		if result.IsSSL && result.SSLExpiry != "" {
+			sslSum += math.Max(0, math.Min(1, float64(result.SSLDaysLeft)/SSLExpiryWarning))
+			sslCount++
+		}
+
+		for _, step := range result.Steps {
+			steps++
+			if step.Passed {
+				passed++
+			}
+		}
+	}
+
+	weights := m.config.ScoreWeights
+	total := weights.Uptime * uptimePercent / 100
+	weightSum := weights.Uptime
+
+	if latencyCount > 0 {
+		total += weights.Latency * latencySum / float64(latencyCount)
+		weightSum += weights.Latency
+	}
+	if sslCount > 0 {
+		total += weights.SSL * sslSum / float64(sslCount)
+		weightSum += weights.SSL
+	}
+	if steps > 0 {
+		total += weights.Assertions * float64(passed) / float64(steps)
+		weightSum += weights.Assertions
+	}
+
+	if weightSum == 0 {
+		return 0
+	}
+	return math.Round(total/weightSum*1000) / 10
+}
+
+// latencyScore is 1 at or below the domain's fast threshold, falling linearly
+// to 0 at its degraded threshold
+func latencyScore(responseTime int64, settings DomainConfig) float64 {
+	fast, degraded := settings.FastUnder(), settings.DegradedAfter()
+	if responseTime <= fast {
+		return 1
+	}
+	if degraded <= fast || responseTime >= degraded {
+		return 0
+	}
+	return 1 - float64(responseTime-fast)/float64(degraded-fast)
+}
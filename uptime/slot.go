@@ -0,0 +1,68 @@
+package uptime
+
+import (
+	"context"
+	"time"
+)
+
+type slotKey struct{}
+
+// checkSlot is one RunCheck concurrency slot held by a check. It travels in
+// the check's context so retry backoff can hand the slot to another domain
+// while it sleeps instead of starving the pool.
+type checkSlot struct {
+	sem  chan struct{}
+	held bool
+}
+
+// acquire blocks until a slot is free or ctx is done
+func (s *checkSlot) acquire(ctx context.Context) error {
+	if s.held {
+		return nil
+	}
+	select {
+	case s.sem <- struct{}{}:
+		s.held = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot if it is held
+func (s *checkSlot) release() {
+	if s.held {
+		<-s.sem
+		s.held = false
+	}
+}
+
+// withSlot attaches slot to ctx for sleepBackoff
+func withSlot(ctx context.Context, slot *checkSlot) context.Context {
+	return context.WithValue(ctx, slotKey{}, slot)
+}
+
+// sleepBackoff waits for d, releasing the caller's concurrency slot (if any)
+// for the duration of the sleep and reacquiring it before returning. It
+// returns ctx.Err() if the context ends first, in which case the slot is not
+// reacquired.
+func sleepBackoff(ctx context.Context, d time.Duration) error {
+	slot, _ := ctx.Value(slotKey{}).(*checkSlot)
+	if slot != nil {
+		slot.release()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+
+	if slot != nil {
+		return slot.acquire(ctx)
+	}
+	return nil
+}
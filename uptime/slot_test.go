@@ -0,0 +1,86 @@
+package uptime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestSleepBackoffFreesSlot(t *testing.T) {
+	sem := make(chan struct{}, 1)
+	slot := &checkSlot{sem: sem}
+	if err := slot.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sleepBackoff(withSlot(context.Background(), slot), 200*time.Millisecond)
+	}()
+
+	// Another check can take the only slot while the first one sleeps
+	other := &checkSlot{sem: sem}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := other.acquire(ctx); err != nil {
+		t.Fatalf("slot not freed during backoff: %v", err)
+	}
+	other.release()
+
+	if err := <-done; err != nil {
+		t.Fatalf("sleepBackoff() error: %v", err)
+	}
+	if !slot.held {
+		t.Error("slot not reacquired after backoff")
+	}
+	slot.release()
+}
+
+func TestRunCheckSlowRetrierDoesNotBlockOthers(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string][]time.Time{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path] = append(seen[r.URL.Path], time.Now())
+		mu.Unlock()
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// One slot and a long backoff: the healthy domain only gets checked
+	// before the retry if the failing one gives up its slot while waiting
+	t.Setenv("MONITOR_DOMAINS", server.URL+"/failing,"+server.URL+"/healthy")
+	t.Setenv("MONITOR_CONCURRENT", "1")
+	t.Setenv("RETRY_MAX", "1")
+	t.Setenv("RETRY_INITIAL_BACKOFF", "300ms")
+	t.Setenv("RETRY_MAX_BACKOFF", "300ms")
+
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewUptimeMonitor(config, zap.NewNop())
+
+	if _, err := m.RunCheck(context.Background()); err != nil {
+		t.Fatalf("RunCheck() error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	failing, healthy := seen["/failing"], seen["/healthy"]
+	if len(failing) != 2 || len(healthy) != 1 {
+		t.Fatalf("requests: failing %d, healthy %d; want 2 and 1", len(failing), len(healthy))
+	}
+	if !healthy[0].Before(failing[1]) {
+		t.Error("healthy domain was checked only after the failing domain's retry")
+	}
+}
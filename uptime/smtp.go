@@ -0,0 +1,268 @@
+package uptime
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SMTPTLSAuto     = "auto"     // implicit TLS on port 465, STARTTLS otherwise
+	SMTPTLSImplicit = "implicit" // TLS from the first byte (SMTPS, usually port 465)
+	SMTPTLSStartTLS = "starttls" // plain connection upgraded with STARTTLS (usually port 587)
+
+	DefaultSMTPPort = "587"
+
+	// smtpTimeout bounds connecting to the SMTP server and, separately, the
+	// whole session that follows, so an unresponsive server can't hang a run
+	smtpTimeout = 30 * time.Second
+)
+
+// parseSMTPTLSMode validates SMTP_TLS_MODE
+func parseSMTPTLSMode(raw string) (string, error) {
+	mode := strings.ToLower(raw)
+	switch mode {
+	case "":
+		return SMTPTLSAuto, nil
+	case SMTPTLSAuto, SMTPTLSImplicit, SMTPTLSStartTLS:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("SMTP_TLS_MODE must be auto, implicit or starttls, got %q", raw)
+	}
+}
+
+// smtpTLSMode resolves auto to the mode implied by the port
+func smtpTLSMode(mode, port string) string {
+	if mode != SMTPTLSAuto {
+		return mode
+	}
+	if port == "465" {
+		return SMTPTLSImplicit
+	}
+	return SMTPTLSStartTLS
+}
+
+// dialSMTP connects to the SMTP server and secures the session, verifying the
+// server certificate against host. The caller must Close the client.
+func dialSMTP(host, port, mode string) (*smtp.Client, error) {
+	addr := net.JoinHostPort(host, port)
+	tlsConfig := &tls.Config{ServerName: host}
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	implicit := smtpTLSMode(mode, port) == SMTPTLSImplicit
+	if implicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s over TLS: %w", addr, err)
+		}
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	if !implicit {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// deliverSMTP sends message from from to every recipient over an
+// authenticated, TLS-secured SMTP session
+func deliverSMTP(client *smtp.Client, auth smtp.Auth, from string, to []string, message []byte) error {
+	if err := client.Auth(auth); err != nil {
+		if xa, ok := auth.(*xoauth2Auth); ok {
+			return xa.authError(err)
+		}
+		return fmt.Errorf("SMTP authentication failed: %w", err)
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+const (
+	SMTPAuthPlain   = "plain"
+	SMTPAuthXOAuth2 = "xoauth2"
+
+	DefaultSMTPOAuthTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// SMTPOAuthConfig holds the credentials for XOAUTH2. A static AccessToken is
+// used as is; with a RefreshToken a fresh access token is requested from
+// TokenURL whenever the current one has expired.
+type SMTPOAuthConfig struct {
+	AccessToken  string
+	RefreshToken string
+	ClientID     string
+	ClientSecret string
+	TokenURL     string
+}
+
+// parseSMTPOAuthConfig reads SMTP_AUTH_METHOD and, for xoauth2, the SMTP_OAUTH_* settings
+func parseSMTPOAuthConfig() (string, *SMTPOAuthConfig, error) {
+	method := strings.ToLower(getEnvOrDefault("SMTP_AUTH_METHOD", SMTPAuthPlain))
+	switch method {
+	case SMTPAuthPlain:
+		return method, nil, nil
+	case SMTPAuthXOAuth2:
+	default:
+		return "", nil, fmt.Errorf("SMTP_AUTH_METHOD must be plain or xoauth2, got %q", method)
+	}
+
+	cfg := &SMTPOAuthConfig{
+		AccessToken:  getEnv("SMTP_OAUTH_ACCESS_TOKEN"),
+		RefreshToken: getEnv("SMTP_OAUTH_REFRESH_TOKEN"),
+		ClientID:     getEnv("SMTP_OAUTH_CLIENT_ID"),
+		ClientSecret: getEnv("SMTP_OAUTH_CLIENT_SECRET"),
+		TokenURL:     getEnvOrDefault("SMTP_OAUTH_TOKEN_URL", DefaultSMTPOAuthTokenURL),
+	}
+
+	if cfg.RefreshToken != "" && cfg.ClientID == "" {
+		return "", nil, fmt.Errorf("SMTP_OAUTH_CLIENT_ID is required with SMTP_OAUTH_REFRESH_TOKEN")
+	}
+	if cfg.AccessToken == "" && cfg.RefreshToken == "" {
+		return "", nil, fmt.Errorf("SMTP_AUTH_METHOD=xoauth2 requires SMTP_OAUTH_ACCESS_TOKEN or SMTP_OAUTH_REFRESH_TOKEN")
+	}
+	return method, cfg, nil
+}
+
+// smtpTokenSource hands out XOAUTH2 access tokens, refreshing them when needed
+type smtpTokenSource struct {
+	config *SMTPOAuthConfig
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newSMTPTokenSource(config *SMTPOAuthConfig, client *http.Client) *smtpTokenSource {
+	return &smtpTokenSource{config: config, client: client, token: config.AccessToken}
+}
+
+// Token returns a usable access token. Without a refresh token the configured
+// access token is returned unchanged.
+func (s *smtpTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.config.RefreshToken == "" {
+		return s.token, nil
+	}
+	// Refresh a minute early so the token doesn't expire mid-session
+	if s.token != "" && time.Until(s.expiry) > time.Minute {
+		return s.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.config.RefreshToken},
+		"client_id":     {s.config.ClientID},
+		"client_secret": {s.config.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh SMTP access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode >= 400 || body.AccessToken == "" {
+		if body.Error == "invalid_grant" {
+			return "", fmt.Errorf("SMTP refresh token was rejected as expired or revoked; issue a new SMTP_OAUTH_REFRESH_TOKEN: %s", body.ErrorDescription)
+		}
+		return "", fmt.Errorf("failed to refresh SMTP access token (status %d): %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+
+	s.token = body.AccessToken
+	s.expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// xoauth2Auth implements smtp.Auth for the XOAUTH2 SASL mechanism used by
+// Gmail and Office 365
+type xoauth2Auth struct {
+	username, token string
+	serverError     string // JSON error challenge sent by the server on failure
+}
+
+func (a *xoauth2Auth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS {
+		return "", nil, fmt.Errorf("refusing XOAUTH2 over an unencrypted connection")
+	}
+	return "XOAUTH2", []byte("user=" + a.username + "\x01auth=Bearer " + a.token + "\x01\x01"), nil
+}
+
+func (a *xoauth2Auth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if more {
+		// On failure the server sends a JSON challenge describing the error and
+		// expects an empty response before it returns the final status
+		a.serverError = string(fromServer)
+		return []byte{}, nil
+	}
+	return nil, nil
+}
+
+// authError explains a rejected XOAUTH2 login, pointing at token expiry
+func (a *xoauth2Auth) authError(err error) error {
+	var detail struct {
+		Status string `json:"status"`
+	}
+	if a.serverError != "" && json.Unmarshal([]byte(a.serverError), &detail) == nil && detail.Status == "401" {
+		return fmt.Errorf("XOAUTH2 access token is invalid or expired (server said %s); refresh it or configure SMTP_OAUTH_REFRESH_TOKEN: %w", a.serverError, err)
+	}
+	return fmt.Errorf("XOAUTH2 authentication failed: %w", err)
+}
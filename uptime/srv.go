@@ -0,0 +1,101 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SRVPrefix marks a MONITOR_DOMAINS entry whose targets are discovered from a
+// DNS SRV record, e.g. srv:_http._tcp.example.com
+const SRVPrefix = "srv:"
+
+// isSRVDomain reports whether domain is an SRV entry
+func isSRVDomain(domain string) bool {
+	return strings.HasPrefix(domain, SRVPrefix)
+}
+
+// srvExpansion is the domain list for one run with SRV entries replaced by
+// their resolved targets
+type srvExpansion struct {
+	domains []string
+	groups  map[string]string   // target -> SRV entry it was resolved from
+	targets map[string][]string // SRV entry -> resolved targets
+	errors  map[string]error    // SRV entries that could not be resolved
+}
+
+// expandSRV resolves every SRV entry in domains. Entries that fail to resolve
+// are kept so they can be reported as down.
+func (m *UptimeMonitor) expandSRV(ctx context.Context, domains []string) srvExpansion {
+	exp := srvExpansion{
+		groups:  make(map[string]string),
+		targets: make(map[string][]string),
+		errors:  make(map[string]error),
+	}
+
+	for _, domain := range domains {
+		if !isSRVDomain(domain) {
+			exp.domains = append(exp.domains, domain)
+			continue
+		}
+
+		_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", strings.TrimPrefix(domain, SRVPrefix))
+		if err == nil && len(records) == 0 {
+			err = fmt.Errorf("no targets")
+		}
+		if err != nil {
+			m.logger.Warn("Failed to resolve SRV record",
+				zap.String("domain", domain),
+				zap.Error(err))
+			exp.errors[domain] = err
+			exp.domains = append(exp.domains, domain)
+			continue
+		}
+
+		for _, record := range records {
+			target := net.JoinHostPort(strings.TrimSuffix(record.Target, "."), fmt.Sprint(record.Port))
+			if _, dup := exp.groups[target]; dup {
+				continue
+			}
+			exp.groups[target] = domain
+			exp.targets[domain] = append(exp.targets[domain], target)
+			exp.domains = append(exp.domains, target)
+		}
+
+		m.logger.Info("Resolved SRV targets",
+			zap.String("domain", domain),
+			zap.Strings("targets", exp.targets[domain]))
+	}
+
+	return exp
+}
+
+// srvFailedResult builds the result for an SRV entry that could not be resolved
+func srvFailedResult(domain string, err error) HealthCheckResult {
+	return HealthCheckResult{
+		Domain:       domain,
+		URL:          redactURL(domain),
+		Group:        domain,
+		Status:       StatusDown,
+		ErrorMessage: fmt.Sprintf("SRV lookup failed: %v", err),
+		Timestamp:    time.Now(),
+		CheckedAt:    time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// domainSettings returns the overrides for domain; SRV targets use the
+// settings of the entry they were resolved from
+func (m *UptimeMonitor) domainSettings(domain string) DomainConfig {
+	m.srvMu.RLock()
+	group, ok := m.srvGroups[domain]
+	m.srvMu.RUnlock()
+
+	if ok {
+		return m.config.DomainSettings(group)
+	}
+	return m.config.DomainSettings(domain)
+}
@@ -0,0 +1,307 @@
+package uptime
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChartStorage uploads a rendered PNG chart and returns a URL email clients
+// can load it from. An empty URL with a nil error means the chart was kept
+// but can't be linked, so it is embedded instead.
+type ChartStorage interface {
+	Upload(data []byte) (string, error)
+}
+
+// ErrChartUploadFailed wraps the last error once every upload attempt has
+// failed, telling the caller to embed the chart instead of linking it
+var ErrChartUploadFailed = errors.New("chart upload failed")
+
+// DefaultChartUploadTimeout bounds an upload, retries included, so a slow
+// storage backend can't hold up the report email
+const DefaultChartUploadTimeout = 30 * time.Second
+
+// chartUploader is a storage backend. Each call is a single attempt to store
+// data under key.
+type chartUploader interface {
+	upload(ctx context.Context, key string, data []byte) (string, error)
+}
+
+// storageStatusError is an HTTP error response from a storage backend
+type storageStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *storageStatusError) Error() string {
+	return fmt.Sprintf("storage returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// retryingChartStorage runs a backend's uploads under a deadline, retrying
+// transient failures with the CHART_UPLOAD_RETRY backoff
+type retryingChartStorage struct {
+	backend chartUploader
+	retry   RetryConfig
+	timeout time.Duration
+}
+
+func (s *retryingChartStorage) Upload(data []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	key := chartObjectKey(time.Now())
+	var lastErr error
+
+	for attempt := 0; attempt <= s.retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return "", fmt.Errorf("%w after %d attempts: %v", ErrChartUploadFailed, attempt, lastErr)
+			case <-time.After(s.retry.CalculateBackoff(attempt - 1)):
+			}
+		}
+
+		link, err := s.backend.upload(ctx, key, data)
+		if err == nil {
+			return link, nil
+		}
+		lastErr = err
+
+		var statusErr *storageStatusError
+		if ctx.Err() != nil || (errors.As(err, &statusErr) && !IsRetryableError(nil, statusErr.StatusCode)) {
+			return "", fmt.Errorf("%w after %d attempts: %v", ErrChartUploadFailed, attempt+1, lastErr)
+		}
+	}
+
+	return "", fmt.Errorf("%w after %d attempts: %v", ErrChartUploadFailed, s.retry.MaxRetries+1, lastErr)
+}
+
+const (
+	ChartStorageSupabase = "supabase"
+	ChartStorageS3       = "s3"    // AWS S3 or an S3-compatible service such as MinIO
+	ChartStorageLocal    = "local" // OutputDir, for running without remote storage
+)
+
+// defaultChartStorage picks the backend when CHART_STORAGE is unset: whichever
+// remote storage has settings, else the local filesystem
+func defaultChartStorage() string {
+	switch {
+	case getEnv("SUPABASE_URL") != "" && getEnv("SUPABASE_KEY") != "":
+		return ChartStorageSupabase
+	case getEnv("S3_BUCKET") != "":
+		return ChartStorageS3
+	default:
+		return ChartStorageLocal
+	}
+}
+
+// chartObjectKey names an uploaded chart, grouped into a folder per day
+func chartObjectKey(now time.Time) string {
+	return fmt.Sprintf("charts/%s/report_%d.png", now.Format("2006-01-02"), now.UnixNano())
+}
+
+// S3Config holds the S3_* settings for CHART_STORAGE=s3
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string // scheme and host, e.g. https://minio.internal:9000
+	PathStyle       bool   // address the bucket in the path rather than the host (MinIO)
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	ACL             string // optional canned ACL such as public-read
+	PublicURL       string // base URL objects are served from, if not the endpoint
+}
+
+// parseS3Config reads the S3_* settings, falling back to the standard AWS_*
+// credential variables
+func parseS3Config() (*S3Config, error) {
+	cfg := &S3Config{
+		Bucket:          getEnv("S3_BUCKET"),
+		Region:          getEnvOrDefault("S3_REGION", getEnvOrDefault("AWS_REGION", "us-east-1")),
+		Endpoint:        strings.TrimRight(getEnv("S3_ENDPOINT"), "/"),
+		AccessKeyID:     getEnvOrDefault("S3_ACCESS_KEY_ID", getEnv("AWS_ACCESS_KEY_ID")),
+		SecretAccessKey: getEnvOrDefault("S3_SECRET_ACCESS_KEY", getEnv("AWS_SECRET_ACCESS_KEY")),
+		SessionToken:    getEnv("AWS_SESSION_TOKEN"),
+		ACL:             getEnv("S3_ACL"),
+		PublicURL:       strings.TrimRight(getEnv("S3_PUBLIC_URL"), "/"),
+	}
+
+	// Custom endpoints are almost always MinIO-style services without
+	// virtual-hosted buckets
+	cfg.PathStyle = getEnvBool("S3_PATH_STYLE", cfg.Endpoint != "")
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3_BUCKET is required when CHART_STORAGE=s3")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("CHART_STORAGE=s3 needs S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY (or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT must be an http(s) URL, got %q", cfg.Endpoint)
+	}
+	return cfg, nil
+}
+
+// s3ChartStorage uploads charts with a SigV4-signed PutObject request
+type s3ChartStorage struct {
+	config *S3Config
+	client *http.Client
+}
+
+// objectURL returns the URL of key for the S3 API
+func (s *s3ChartStorage) objectURL(key string) *url.URL {
+	u, _ := url.Parse(s.config.Endpoint)
+	if s.config.PathStyle {
+		u.Path = "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return u
+}
+
+func (s *s3ChartStorage) upload(ctx context.Context, key string, data []byte) (string, error) {
+	now := time.Now().UTC()
+	target := s.objectURL(key)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "image/png")
+	if s.config.ACL != "" {
+		req.Header.Set("X-Amz-Acl", s.config.ACL)
+	}
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	signS3Request(req, data, s.config, now)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload chart to S3: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", &storageStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	if s.config.PublicURL != "" {
+		return s.config.PublicURL + "/" + key, nil
+	}
+	return target.String(), nil
+}
+
+// localChartStorage keeps charts out of any remote store. Email clients can't
+// follow a path on this machine, so Upload returns no URL and the chart is
+// embedded in the message; nothing is written to disk, since a copy there
+// would never be referenced.
+type localChartStorage struct{}
+
+func (s *localChartStorage) Upload(data []byte) (string, error) {
+	return "", nil
+}
+
+// signS3Request adds AWS Signature Version 4 headers for a single-chunk
+// upload of payload
+func signS3Request(req *http.Request, payload []byte, cfg *S3Config, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Sign the host, the content type and every x-amz-* header
+	signed := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			signed[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// newChartStorage returns the backend named by CHART_STORAGE, wrapped with
+// the upload timeout and retries
+func newChartStorage(config *MonitorConfig, client *http.Client) ChartStorage {
+	var backend chartUploader
+	switch config.ChartStorage {
+	case ChartStorageLocal:
+		return &localChartStorage{}
+	case ChartStorageS3:
+		backend = &s3ChartStorage{config: config.S3, client: client}
+	default:
+		backend = newSupabaseChartStorage(client, config.SupabaseBucket, config.SupabaseBucketPrivate, config.ChartURLExpiry)
+	}
+
+	return &retryingChartStorage{
+		backend: backend,
+		retry:   config.ChartUploadRetry,
+		timeout: config.ChartUploadTimeout,
+	}
+}
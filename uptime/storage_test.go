@@ -0,0 +1,59 @@
+package uptime
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// stubUploader fails with each of statuses in turn, then returns link
+type stubUploader struct {
+	statuses []int
+	link     string
+	attempts int
+}
+
+func (u *stubUploader) upload(ctx context.Context, key string, data []byte) (string, error) {
+	u.attempts++
+	if u.attempts <= len(u.statuses) {
+		return "", &storageStatusError{StatusCode: u.statuses[u.attempts-1], Body: "unavailable"}
+	}
+	return u.link, nil
+}
+
+func TestRetryingChartStorageRetriesServerErrors(t *testing.T) {
+	backend := &stubUploader{statuses: []int{500, 500}, link: "https://charts.example.com/chart.png"}
+	storage := &retryingChartStorage{
+		backend: backend,
+		retry:   RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffMultiplier: 1},
+		timeout: time.Second,
+	}
+
+	link, err := storage.Upload([]byte("png"))
+	if err != nil {
+		t.Fatalf("Upload() error: %v", err)
+	}
+	if link != backend.link {
+		t.Errorf("Upload() = %q, want %q", link, backend.link)
+	}
+	if backend.attempts != 3 {
+		t.Errorf("attempts = %d, want 3", backend.attempts)
+	}
+}
+
+func TestLocalChartStorageWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	config := &MonitorConfig{ChartStorage: ChartStorageLocal, OutputDir: dir}
+
+	link, err := newChartStorage(config, nil).Upload([]byte("png"))
+	if err != nil {
+		t.Fatalf("Upload() error: %v", err)
+	}
+	if link != "" {
+		t.Errorf("Upload() = %q, want no URL so the chart is inlined", link)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("OutputDir has %d entries, want none", len(entries))
+	}
+}
@@ -0,0 +1,135 @@
+package uptime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultIdempotencyHeader carries the submission's idempotency key
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// Headers set on signed API submissions and webhooks
+const (
+	SignatureHeader          = "X-Signature"           // sha256=<hex HMAC>
+	SignatureTimestampHeader = "X-Signature-Timestamp" // Unix seconds
+)
+
+// signRequest sets the HMAC-SHA256, keyed with secret, of
+// "{timestamp}.{body}". The timestamp is signed too, so a receiver that
+// rejects stale timestamps can't be fed a replayed request. body must be the
+// bytes sent on the wire.
+func signRequest(req *http.Request, body []byte, secret string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmacSHA256([]byte(secret), timestamp+"."+string(body))
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac))
+}
+
+// submissionBody is the encoded report sent on every attempt of a submission
+type submissionBody struct {
+	data    []byte
+	gzipped bool
+}
+
+// idempotencyKey identifies a report's content, so every attempt at the same
+// submission carries the same key while each run, with its own timestamps and
+// results, gets a new one
+func idempotencyKey(payload []byte) string {
+	return sha256Hex(payload)[:32]
+}
+
+// encodeSubmission gzips payload when it is at least threshold bytes. A
+// negative threshold disables compression.
+func encodeSubmission(payload []byte, threshold int) (submissionBody, error) {
+	if threshold < 0 || len(payload) < threshold {
+		return submissionBody{data: payload}, nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(payload); err != nil {
+		return submissionBody{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return submissionBody{}, err
+	}
+	return submissionBody{data: buf.Bytes(), gzipped: true}, nil
+}
+
+// submitURLData is what a target URL template is executed with
+type submitURLData struct {
+	Environment string // path-escaped
+	Service     string // path-escaped
+	Timestamp   time.Time
+}
+
+// renderSubmitURL builds the URL a report is submitted to. A URL may contain
+// text/template actions such as {{.Environment}}; one without is used as is.
+func renderSubmitURL(raw string, report *MonitorReport) (string, error) {
+	if !strings.Contains(raw, "{{") {
+		return raw, nil
+	}
+
+	tmpl, err := template.New("api_url").Option("missingkey=error").Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, submitURLData{
+		Environment: url.PathEscape(report.Environment),
+		Service:     url.PathEscape(report.Service),
+		Timestamp:   report.Timestamp,
+	}); err != nil {
+		return "", fmt.Errorf("failed to render URL template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// validateAPITarget checks a target's method and renders its URL with sample
+// data, so a bad template fails at startup rather than after the first run
+func validateAPITarget(target APITarget) error {
+	switch target.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("method must be POST, PUT or PATCH, got %q", target.Method)
+	}
+
+	rendered, err := renderSubmitURL(target.URL, &MonitorReport{
+		Service:     "Uptime Monitor",
+		Environment: "production",
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(rendered); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an http(s) URL, got %q", rendered)
+	}
+	return nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name (an
+// RFC 7230 token)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
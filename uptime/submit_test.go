@@ -0,0 +1,148 @@
+package uptime
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newSubmitTestMonitor returns a monitor submitting to url with the current
+// environment's settings
+func newSubmitTestMonitor(t *testing.T, url string) *UptimeMonitor {
+	t.Helper()
+	t.Setenv("MONITOR_DOMAINS", "example.com")
+	t.Setenv("API_URL", url)
+	t.Setenv("RETRY_MAX", "0")
+
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewUptimeMonitor(config, zap.NewNop())
+}
+
+func testSubmitReport() *MonitorReport {
+	return &MonitorReport{
+		Service:       "Uptime Monitor",
+		TotalChecks:   1,
+		Uptime:        1,
+		UptimePercent: 100,
+		Timestamp:     time.Date(2025, 11, 9, 10, 30, 0, 0, time.UTC),
+		Results: []HealthCheckResult{
+			{Domain: "example.com", URL: "https://example.com", Status: "up", StatusCode: 200},
+		},
+	}
+}
+
+func TestSubmitToTargetGzipsPayload(t *testing.T) {
+	var received *MonitorReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip", got)
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("body is not gzip: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		if err := json.NewDecoder(gz).Decode(&received); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("API_GZIP_THRESHOLD", "0")
+	m := newSubmitTestMonitor(t, server.URL)
+	report := testSubmitReport()
+
+	if err := m.submitToTarget(context.Background(), m.config.APITargets[0], report); err != nil {
+		t.Fatalf("submitToTarget() error: %v", err)
+	}
+	if !reflect.DeepEqual(received, report) {
+		t.Errorf("server received %+v, want %+v", received, report)
+	}
+}
+
+func TestSubmitToTargetSignsWireBody(t *testing.T) {
+	const secret = "s3cret"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+
+		timestamp := r.Header.Get(SignatureTimestampHeader)
+		if ts, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+			t.Errorf("%s = %q, want a current Unix timestamp", SignatureTimestampHeader, timestamp)
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "." + string(body)))
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get(SignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Signed over the compressed bytes, as they are sent
+	t.Setenv("API_SIGNING_SECRET", secret)
+	t.Setenv("API_GZIP_THRESHOLD", "0")
+	m := newSubmitTestMonitor(t, server.URL)
+
+	if err := m.submitToTarget(context.Background(), m.config.APITargets[0], testSubmitReport()); err != nil {
+		t.Fatalf("submitToTarget() error: %v", err)
+	}
+}
+
+func TestSubmitToTargetReusesConnectionAcrossRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			// A body larger than the transport's buffer must be drained for
+			// the connection to go back to the pool
+			http.Error(w, strings.Repeat("unavailable ", 1000), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("SUBMIT_RETRY_MAX_RETRIES", "2")
+	t.Setenv("SUBMIT_RETRY_INITIAL_BACKOFF", "1ms")
+	t.Setenv("SUBMIT_RETRY_MAX_BACKOFF", "1ms")
+	m := newSubmitTestMonitor(t, server.URL)
+
+	var reused []bool
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			reused = append(reused, info.Reused)
+		},
+	})
+
+	if err := m.submitToTarget(ctx, m.config.APITargets[0], testSubmitReport()); err != nil {
+		t.Fatalf("submitToTarget() error: %v", err)
+	}
+	if want := []bool{false, true, true}; !reflect.DeepEqual(reused, want) {
+		t.Errorf("GotConn reused = %v, want %v", reused, want)
+	}
+}
@@ -0,0 +1,287 @@
+package uptime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultChartBucket is the Supabase storage bucket charts are uploaded to
+	DefaultChartBucket = "uptime-charts"
+
+	// DefaultPrivateChartURLExpiry is how long signed chart links last when
+	// the bucket is private and CHART_URL_EXPIRY is unset
+	DefaultPrivateChartURLExpiry = 7 * 24 * time.Hour
+
+	// Signed chart URLs must last between these bounds
+	MinChartURLExpiry = time.Minute
+	MaxChartURLExpiry = 365 * 24 * time.Hour
+)
+
+var supabaseBucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// supabaseChartStorage uploads charts to a Supabase storage bucket, creating
+// it on first use. It talks to the storage REST API directly so every request
+// honours the upload deadline.
+type supabaseChartStorage struct {
+	baseURL   string // {SUPABASE_URL}/storage/v1
+	key       string
+	bucket    string
+	private   bool          // create the bucket private; links are always signed
+	urlExpiry time.Duration // sign URLs valid this long; 0 links the public URL
+	client    *http.Client
+
+	mu          sync.Mutex
+	bucketReady bool
+}
+
+func newSupabaseChartStorage(client *http.Client, bucket string, private bool, urlExpiry time.Duration) *supabaseChartStorage {
+	if private && urlExpiry <= 0 {
+		urlExpiry = DefaultPrivateChartURLExpiry
+	}
+	return &supabaseChartStorage{
+		baseURL:   strings.TrimRight(getEnv("SUPABASE_URL"), "/") + "/storage/v1",
+		key:       getEnv("SUPABASE_KEY"),
+		bucket:    bucket,
+		private:   private,
+		urlExpiry: urlExpiry,
+		client:    client,
+	}
+}
+
+func (s *supabaseChartStorage) upload(ctx context.Context, key string, data []byte) (string, error) {
+	if err := s.ensureBucket(ctx); err != nil {
+		return "", err
+	}
+
+	if _, err := s.do(ctx, http.MethodPost, "/object/"+s.bucket+"/"+key, "image/png", data); err != nil {
+		return "", fmt.Errorf("failed to upload chart: %w", err)
+	}
+
+	// A signed URL is requested for private buckets and whenever an expiry is
+	// configured; otherwise the bucket's public URL is used
+	if s.urlExpiry <= 0 {
+		return s.baseURL + "/object/public/" + s.bucket + "/" + key, nil
+	}
+
+	body, _ := json.Marshal(map[string]int{"expiresIn": int(s.urlExpiry.Seconds())})
+	resp, err := s.do(ctx, http.MethodPost, "/object/sign/"+s.bucket+"/"+key, "application/json", body)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign chart url: %w", err)
+	}
+	var signed struct {
+		SignedURL string `json:"signedURL"`
+	}
+	if err := json.Unmarshal(resp, &signed); err != nil || signed.SignedURL == "" {
+		return "", fmt.Errorf("storage returned no signed url for %s", key)
+	}
+	return s.baseURL + signed.SignedURL, nil
+}
+
+// ensureBucket creates the chart bucket unless it is known to exist. An
+// existing bucket's visibility is left as it is.
+func (s *supabaseChartStorage) ensureBucket(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bucketReady {
+		return nil
+	}
+
+	_, err := s.do(ctx, http.MethodGet, "/bucket/"+s.bucket, "", nil)
+	var statusErr *storageStatusError
+	if err != nil && errors.As(err, &statusErr) && !IsRetryableError(nil, statusErr.StatusCode) {
+		// Supabase answers 400 or 404 for a missing bucket
+		body, _ := json.Marshal(map[string]any{"id": s.bucket, "name": s.bucket, "public": !s.private})
+		if _, err = s.do(ctx, http.MethodPost, "/bucket", "application/json", body); err != nil {
+			return fmt.Errorf("failed to create bucket: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to look up bucket: %w", err)
+	}
+
+	s.bucketReady = true
+	return nil
+}
+
+// do sends one storage API request and returns the response body, turning
+// HTTP errors into *storageStatusError
+func (s *supabaseChartStorage) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.key)
+	req.Header.Set("apikey", s.key)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 300 {
+		return nil, &storageStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+	return respBody, nil
+}
+
+// DefaultResultsTable is the Supabase table check results are read from and written to
+const DefaultResultsTable = "health_checks"
+
+// resultsTable returns the configured Supabase results table
+func resultsTable() string {
+	if table := getEnv("SUPABASE_RESULTS_TABLE"); table != "" {
+		return table
+	}
+	return DefaultResultsTable
+}
+
+// queryDataFromSupabase fetches past check rows through PostgREST. query is a
+// PostgREST query string, e.g. buildResultsQuery's output or
+// "domain=eq.example.com&checked_at=gte.2025-01-01T00:00:00Z".
+func queryDataFromSupabase(query string) ([]map[string]interface{}, error) {
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	if !params.Has("select") {
+		params.Set("select", "*")
+	}
+
+	body, err := supabaseREST(http.MethodGet, params.Encode(), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("supabase query failed: %w", err)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse supabase response: %w", err)
+	}
+	return rows, nil
+}
+
+// buildResultsQuery builds a PostgREST query for one domain's rows within a
+// time range, newest first. Empty or zero arguments are left unfiltered.
+func buildResultsQuery(domain string, since, until time.Time) string {
+	params := url.Values{}
+	if domain != "" {
+		params.Set("domain", "eq."+domain)
+	}
+	var checkedAt []string
+	if !since.IsZero() {
+		checkedAt = append(checkedAt, "checked_at.gte."+since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		checkedAt = append(checkedAt, "checked_at.lt."+until.UTC().Format(time.RFC3339))
+	}
+	if len(checkedAt) > 0 {
+		params.Set("and", "("+strings.Join(checkedAt, ",")+")")
+	}
+	params.Set("order", "checked_at.desc")
+	return params.Encode()
+}
+
+// resultRow is the row stored in the results table for each check
+type resultRow struct {
+	Domain       string `json:"domain"`
+	Environment  string `json:"environment,omitempty"`
+	Status       string `json:"status"`
+	StatusCode   int    `json:"status_code"`
+	ResponseTime int64  `json:"response_time_ms"`
+	SSLDaysLeft  int    `json:"ssl_days_left"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	CheckedAt    string `json:"checked_at"`
+}
+
+// storeResults inserts one row per check result into the results table in a
+// single batched request
+func storeResults(report *MonitorReport) error {
+	if len(report.Results) == 0 {
+		return nil
+	}
+
+	rows := make([]resultRow, 0, len(report.Results))
+	for _, result := range report.Results {
+		rows = append(rows, resultRow{
+			Domain:       result.Domain,
+			Environment:  report.Environment,
+			Status:       result.Status,
+			StatusCode:   result.StatusCode,
+			ResponseTime: result.ResponseTime,
+			SSLDaysLeft:  result.SSLDaysLeft,
+			ErrorMessage: result.ErrorMessage,
+			CheckedAt:    result.CheckedAt,
+		})
+	}
+
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result rows: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Prefer":       "return=minimal",
+	}
+	if _, err := supabaseREST(http.MethodPost, "", bytes.NewReader(data), headers); err != nil {
+		return fmt.Errorf("failed to store results: %w", err)
+	}
+	return nil
+}
+
+// supabaseREST sends a PostgREST request against the results table and
+// returns the response body, failing on HTTP errors
+func supabaseREST(method, query string, body io.Reader, headers map[string]string) ([]byte, error) {
+	supabaseURL := getEnv("SUPABASE_URL")
+	supabaseKey := getEnv("SUPABASE_KEY")
+
+	if supabaseURL == "" || supabaseKey == "" {
+		return nil, fmt.Errorf("missing SUPABASE_URL or SUPABASE_KEY environment variables")
+	}
+
+	endpoint := fmt.Sprintf("%s/rest/v1/%s", strings.TrimRight(supabaseURL, "/"), url.PathEscape(resultsTable()))
+	if query != "" {
+		endpoint += "?" + query
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("apikey", supabaseKey)
+	req.Header.Set("Authorization", "Bearer "+supabaseKey)
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: DefaultTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
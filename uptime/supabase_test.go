@@ -0,0 +1,73 @@
+package uptime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryDataFromSupabase(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method = %s, want GET", r.Method)
+		}
+		if r.URL.Path != "/rest/v1/checks" {
+			t.Errorf("path = %q, want /rest/v1/checks", r.URL.Path)
+		}
+		if got := r.Header.Get("apikey"); got != "service-key" {
+			t.Errorf("apikey = %q, want service-key", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer service-key" {
+			t.Errorf("Authorization = %q, want Bearer service-key", got)
+		}
+
+		query := r.URL.Query()
+		for key, want := range map[string]string{
+			"select": "*",
+			"domain": "eq.example.com",
+			"and":    "(checked_at.gte.2025-11-01T00:00:00Z,checked_at.lt.2025-11-02T00:00:00Z)",
+			"order":  "checked_at.desc",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("query %s = %q, want %q", key, got, want)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"domain":"example.com","status":"up","status_code":200},{"domain":"example.com","status":"down","status_code":503}]`))
+	}))
+	defer server.Close()
+
+	t.Setenv("SUPABASE_URL", server.URL+"/")
+	t.Setenv("SUPABASE_KEY", "service-key")
+	t.Setenv("SUPABASE_RESULTS_TABLE", "checks")
+
+	since := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	rows, err := queryDataFromSupabase(buildResultsQuery("example.com", since, since.AddDate(0, 0, 1)))
+	if err != nil {
+		t.Fatalf("queryDataFromSupabase() error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0]["status"] != "up" || rows[1]["status_code"] != float64(503) {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestQueryDataFromSupabaseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"relation does not exist"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	t.Setenv("SUPABASE_URL", server.URL)
+	t.Setenv("SUPABASE_KEY", "service-key")
+
+	_, err := queryDataFromSupabase("domain=eq.example.com")
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("queryDataFromSupabase() error = %v, want a status 404 error", err)
+	}
+}
@@ -0,0 +1,97 @@
+package uptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TCPScheme marks a MONITOR_DOMAINS entry checked with a raw TCP dial, e.g.
+// tcp://db.internal:5432
+const TCPScheme = "tcp://"
+
+// isTCPDomain reports whether domain is a TCP port check
+func isTCPDomain(domain string) bool {
+	return strings.HasPrefix(domain, TCPScheme)
+}
+
+// checkTCP dials the domain's host:port and reports it up when the connection
+// is established within the timeout. Connect latency is judged against the
+// domain's thresholds like an HTTP response time.
+func (m *UptimeMonitor) checkTCP(ctx context.Context, domain string, settings DomainConfig) HealthCheckResult {
+	retryConfig := m.config.CheckRetry
+	address := strings.TrimPrefix(domain, TCPScheme)
+
+	var result HealthCheckResult
+	var retryDelay time.Duration
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
+		result = HealthCheckResult{
+			Domain:          domain,
+			URL:             redactURL(domain),
+			Attempts:        attempt + 1,
+			RetryDelayTotal: retryDelay.Milliseconds(),
+			Timestamp:       time.Now(),
+			CheckedAt:       time.Now().UTC().Format(time.RFC3339),
+		}
+
+		if err := m.waitRateLimit(ctx, attempt); err != nil {
+			result.Status = StatusDown
+			result.ErrorMessage = fmt.Sprintf("Rate limiter error: %v", err)
+			return result
+		}
+
+		timeout := settings.RequestTimeout(m.config.Timeout)
+		result.Timeout = timeout.Milliseconds()
+		dialCtx, cancel := context.WithTimeout(ctx, timeout)
+		startTime := time.Now()
+		conn, err := m.transport.DialContext(dialCtx, "tcp", address)
+		result.ResponseTime = time.Since(startTime).Milliseconds()
+		result.ConnectTime = result.ResponseTime
+		cancel()
+
+		if err == nil {
+			result.SourceAddress = conn.LocalAddr().String()
+			conn.Close()
+
+			result.FastThreshold = settings.FastUnder()
+			result.DegradedThreshold = settings.DegradedAfter()
+			result.DownThreshold = settings.DownAfter()
+			switch {
+			case settings.DownAfter() > 0 && result.ResponseTime >= settings.DownAfter():
+				result.Status = StatusDown
+			case result.ResponseTime >= settings.DegradedAfter():
+				result.Status = StatusDegraded
+			default:
+				result.Status = StatusUp
+			}
+			return result
+		}
+
+		result.Status = StatusDown
+		result.ErrorMessage = fmt.Sprintf("Connection failed: %v", err)
+		result.FailureReason = classifyFailure(err)
+
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) || attempt == retryConfig.MaxRetries {
+			break
+		}
+
+		backoff := retryConfig.CalculateBackoff(attempt)
+		retryDelay += backoff
+		if err := sleepBackoff(ctx, backoff); err != nil {
+			result.ErrorMessage = "Context cancelled during retry"
+			return result
+		}
+	}
+
+	m.logger.Warn("TCP check failed",
+		zap.String("domain", domain),
+		zap.Int("attempts", result.Attempts),
+		zap.String("error", result.ErrorMessage))
+	return result
+}
@@ -0,0 +1,53 @@
+package uptime
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// parseTLSVersion converts a version such as "1.2" to its crypto/tls constant
+func parseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q (want 1.0, 1.1, 1.2 or 1.3)", v)
+	}
+}
+
+// classifyTLSError returns a short description of a certificate validation
+// failure, or "" when err is not one (e.g. a refused connection or timeout)
+func classifyTLSError(err error) string {
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return fmt.Sprintf("hostname mismatch: %v", hostnameErr)
+	}
+
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) {
+		if invalidErr.Reason == x509.Expired {
+			return fmt.Sprintf("certificate expired or not yet valid: %v", invalidErr)
+		}
+		return fmt.Sprintf("invalid certificate: %v", invalidErr)
+	}
+
+	var authorityErr x509.UnknownAuthorityError
+	if errors.As(err, &authorityErr) {
+		return fmt.Sprintf("untrusted certificate authority: %v", authorityErr)
+	}
+
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return fmt.Sprintf("certificate verification failed: %v", verifyErr.Err)
+	}
+
+	return ""
+}
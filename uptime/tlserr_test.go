@@ -0,0 +1,81 @@
+package uptime
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newSelfSignedServer starts an httptest TLS server, counting the
+// connections made to it and discarding its handshake error logs
+func newSelfSignedServer(conns *atomic.Int32) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.StartTLS()
+	return server
+}
+
+func TestClassifyTLSErrorSelfSigned(t *testing.T) {
+	var conns atomic.Int32
+	server := newSelfSignedServer(&conns)
+	defer server.Close()
+
+	// The default client doesn't trust httptest's self-signed certificate
+	resp, err := http.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected a certificate error")
+	}
+	if got := classifyTLSError(err); !strings.HasPrefix(got, "untrusted certificate authority") {
+		t.Errorf("classifyTLSError() = %q, want an untrusted authority error", got)
+	}
+
+	server.Close()
+	if _, err := http.Get(server.URL); err == nil {
+		t.Fatal("expected a connection error")
+	} else if got := classifyTLSError(err); got != "" {
+		t.Errorf("classifyTLSError() = %q for a refused connection, want empty", got)
+	}
+}
+
+func TestCheckDomainReportsSelfSignedCertificate(t *testing.T) {
+	var conns atomic.Int32
+	server := newSelfSignedServer(&conns)
+	defer server.Close()
+
+	t.Setenv("MONITOR_DOMAINS", server.URL)
+	t.Setenv("RETRY_MAX", "2")
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewUptimeMonitor(config, zap.NewNop())
+
+	result := m.CheckDomain(context.Background(), server.URL)
+	if result.Status != StatusDown {
+		t.Errorf("Status = %q, want %q", result.Status, StatusDown)
+	}
+	if !strings.HasPrefix(result.TLSError, "untrusted certificate authority") {
+		t.Errorf("TLSError = %q, want an untrusted authority error", result.TLSError)
+	}
+	if result.FailureReason != FailureTLS {
+		t.Errorf("FailureReason = %q, want %q", result.FailureReason, FailureTLS)
+	}
+	// A bad certificate isn't retried
+	if got := conns.Load(); got != 1 {
+		t.Errorf("server saw %d connections, want 1", got)
+	}
+}
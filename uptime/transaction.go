@@ -0,0 +1,224 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MaxStepBodySize caps how much of a step's response body is read for assertions
+const MaxStepBodySize = 1 << 20
+
+// TransactionStep is one request of a multi-step synthetic check
+type TransactionStep struct {
+	Name           string            `json:"name"`
+	URL            string            `json:"url"` // absolute, or relative to the domain
+	Method         string            `json:"method,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	ExpectStatus   []int             `json:"expect_status,omitempty"`   // defaults to any 2xx/3xx
+	ExpectContains string            `json:"expect_contains,omitempty"` // substring the body must contain
+}
+
+// StepResult records the outcome of a single transaction step
+type StepResult struct {
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	StatusCode   int    `json:"status_code"`
+	ResponseTime int64  `json:"response_time_ms"`
+	Passed       bool   `json:"passed"`
+	Attempts     int    `json:"attempts"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// checkTransaction runs the domain's steps in order with a shared cookie jar.
+// The check is up only if every step passes; the first failing step stops the
+// transaction and marks the check down.
+func (m *UptimeMonitor) checkTransaction(ctx context.Context, domain string, settings DomainConfig) HealthCheckResult {
+	baseURL := m.checkURL(domain)
+
+	result := HealthCheckResult{
+		Domain:    domain,
+		URL:       redactURL(baseURL),
+		IsSSL:     strings.HasPrefix(baseURL, "https://"),
+		Attempts:  1,
+		Timestamp: time.Now(),
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		result.Status = StatusDown
+		result.ErrorMessage = fmt.Sprintf("Failed to create cookie jar: %v", err)
+		return result
+	}
+
+	base := m.clientFor(settings)
+	client := &http.Client{
+		Timeout:       base.Timeout,
+		Transport:     base.Transport,
+		CheckRedirect: base.CheckRedirect,
+		Jar:           jar,
+	}
+
+	for i, step := range settings.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+
+		stepResult, retryDelay := m.runStep(ctx, client, baseURL, name, step, settings)
+		result.Steps = append(result.Steps, stepResult)
+		result.ResponseTime += stepResult.ResponseTime
+		result.StatusCode = stepResult.StatusCode
+		result.Attempts = max(result.Attempts, stepResult.Attempts)
+		result.RetryDelayTotal += retryDelay.Milliseconds()
+
+		if !stepResult.Passed {
+			result.Status = StatusDown
+			result.FailedStep = name
+			result.ErrorMessage = fmt.Sprintf("Step %q failed: %s", name, stepResult.ErrorMessage)
+			return result
+		}
+	}
+
+	result.FastThreshold = settings.FastUnder()
+	result.DegradedThreshold = settings.DegradedAfter()
+	result.DownThreshold = settings.DownAfter()
+
+	result.Status = StatusUp
+	if down := settings.DownAfter(); down > 0 && result.ResponseTime >= down {
+		result.Status = StatusDown
+	} else if result.ResponseTime >= settings.DegradedAfter() {
+		result.Status = StatusDegraded
+	}
+
+	return result
+}
+
+// runStep performs a transaction step, retrying transient failures on the
+// check retry curve like a single check, and evaluates its assertions. It
+// returns the time spent in backoff between attempts.
+func (m *UptimeMonitor) runStep(ctx context.Context, client *http.Client, baseURL, name string, step TransactionStep, settings DomainConfig) (StepResult, time.Duration) {
+	retryConfig := m.config.CheckRetry
+
+	stepURL, err := resolveStepURL(baseURL, step.URL)
+	if err != nil {
+		return StepResult{Name: name, Attempts: 1, ErrorMessage: fmt.Sprintf("invalid url: %v", err)}, 0
+	}
+
+	var stepResult StepResult
+	var retryDelay time.Duration
+	for attempt := 0; attempt <= retryConfig.MaxRetries; attempt++ {
+		var retryable bool
+		stepResult, retryable = m.runStepAttempt(ctx, client, stepURL, name, step, settings, attempt)
+		stepResult.Attempts = attempt + 1
+		if stepResult.Passed || !retryable || attempt == retryConfig.MaxRetries {
+			break
+		}
+
+		backoff := retryConfig.CalculateBackoff(attempt)
+		retryDelay += backoff
+		if err := sleepBackoff(ctx, backoff); err != nil {
+			stepResult.ErrorMessage = "context cancelled during retry"
+			break
+		}
+	}
+	return stepResult, retryDelay
+}
+
+// runStepAttempt makes one request for a step and evaluates its assertions.
+// retryable reports whether a failure is worth another attempt.
+func (m *UptimeMonitor) runStepAttempt(ctx context.Context, client *http.Client, stepURL, name string, step TransactionStep, settings DomainConfig, attempt int) (stepResult StepResult, retryable bool) {
+	stepResult = StepResult{Name: name, URL: redactURL(stepURL)}
+
+	if err := m.waitRateLimit(ctx, attempt); err != nil {
+		stepResult.ErrorMessage = fmt.Sprintf("rate limiter error: %v", err)
+		return stepResult, false
+	}
+
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(step.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), stepURL, body)
+	if err != nil {
+		stepResult.ErrorMessage = fmt.Sprintf("failed to create request: %v", err)
+		return stepResult, false
+	}
+
+	// The domain's headers, Host override and auth apply to every step; the
+	// step's own headers are set on top
+	m.applyCheckHeaders(req, settings)
+	for key, value := range step.Headers {
+		req.Header.Set(key, value)
+	}
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		stepResult.ResponseTime = time.Since(startTime).Milliseconds()
+		stepResult.ErrorMessage = fmt.Sprintf("request failed: %v", err)
+		return stepResult, IsRetryableError(err, 0)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, MaxStepBodySize))
+	stepResult.ResponseTime = time.Since(startTime).Milliseconds()
+	stepResult.StatusCode = resp.StatusCode
+	if err != nil {
+		stepResult.ErrorMessage = fmt.Sprintf("failed to read body: %v", err)
+		return stepResult, IsRetryableError(err, 0)
+	}
+
+	if !stepStatusMatches(step.ExpectStatus, resp.StatusCode) {
+		stepResult.ErrorMessage = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		return stepResult, IsRetryableError(nil, resp.StatusCode)
+	}
+
+	if step.ExpectContains != "" && !strings.Contains(string(content), step.ExpectContains) {
+		stepResult.ErrorMessage = fmt.Sprintf("body does not contain %q", step.ExpectContains)
+		return stepResult, false
+	}
+
+	stepResult.Passed = true
+	return stepResult, false
+}
+
+// resolveStepURL resolves a step URL relative to the domain's base URL
+func resolveStepURL(baseURL, stepURL string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(stepURL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// stepStatusMatches reports whether code satisfies the expected codes, or is
+// 2xx/3xx when none are configured
+func stepStatusMatches(expected []int, code int) bool {
+	if len(expected) == 0 {
+		return code >= 200 && code < 400
+	}
+	for _, want := range expected {
+		if code == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package uptime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestTransactionStepsCarryDomainAuthAndRetry(t *testing.T) {
+	var accountCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" || r.Host != "app.internal" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// The second step fails once with a transient error
+		if r.URL.Path == "/account" && accountCalls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Setenv("MONITOR_DOMAINS", server.URL)
+	t.Setenv("STEP_TOKEN", "s3cret")
+	t.Setenv("RETRY_MAX", "2")
+	t.Setenv("RETRY_INITIAL_BACKOFF", "10ms")
+	t.Setenv("RETRY_MAX_BACKOFF", "10ms")
+	t.Setenv("MONITOR_DOMAIN_CONFIG", fmt.Sprintf(`{%q:{
+		"auth": {"type": "bearer", "token_env": "STEP_TOKEN"},
+		"host_header": "app.internal",
+		"steps": [{"name": "login", "url": "/login"}, {"name": "account", "url": "/account"}]
+	}}`, server.URL))
+	config, err := NewMonitorConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	m := NewUptimeMonitor(config, zap.NewNop())
+
+	result := m.CheckDomain(context.Background(), server.URL)
+	if result.Status != StatusUp {
+		t.Fatalf("Status = %q (%s), want %q", result.Status, result.ErrorMessage, StatusUp)
+	}
+	if len(result.Steps) != 2 {
+		t.Fatalf("got %d steps, want 2", len(result.Steps))
+	}
+	if got := result.Steps[1].Attempts; got != 2 {
+		t.Errorf("account step took %d attempts, want 2", got)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", result.Attempts)
+	}
+}
@@ -0,0 +1,163 @@
+package uptime
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Validate checks the configuration for values that would make a run fail or
+// misbehave. Every problem is reported, not just the first, so a broken
+// deployment can be fixed in one pass.
+func (c *MonitorConfig) Validate() error {
+	problems := append([]string(nil), c.problems...)
+
+	if len(c.Domains) == 0 {
+		problems = append(problems, "no domains configured: set MONITOR_DOMAINS or MONITOR_DOMAINS_FILE, or list domains in CONFIG_FILE")
+	}
+	for _, domain := range c.Domains {
+		if err := validateDomain(domain); err != nil {
+			problems = append(problems, fmt.Sprintf("domain %q: %v", domain, err))
+		}
+	}
+
+	if c.Timeout <= 0 {
+		problems = append(problems, fmt.Sprintf("MONITOR_TIMEOUT must be greater than zero, got %s", c.Timeout))
+	}
+	if c.Interval < 0 {
+		problems = append(problems, fmt.Sprintf("MONITOR_INTERVAL must not be negative, got %s", c.Interval))
+	}
+	switch c.ExitPolicy {
+	case ExitPolicyDown, ExitPolicyDegraded, ExitPolicyUptime:
+	default:
+		problems = append(problems, fmt.Sprintf("EXIT_POLICY must be down, degraded or uptime, got %q", c.ExitPolicy))
+	}
+	if c.HistorySize < 1 {
+		problems = append(problems, fmt.Sprintf("HISTORY_SIZE must be at least 1, got %d", c.HistorySize))
+	}
+	for _, curve := range []struct {
+		prefix string
+		retry  RetryConfig
+	}{
+		{"CHECK_RETRY", c.CheckRetry},
+		{"SUBMIT_RETRY", c.SubmitRetry},
+		{"WEBHOOK_RETRY", c.WebhookRetry},
+		{"CHART_UPLOAD_RETRY", c.ChartUploadRetry},
+	} {
+		if curve.retry.InitialBackoff > curve.retry.MaxBackoff {
+			problems = append(problems, fmt.Sprintf("%s_INITIAL_BACKOFF (%s) must not exceed %s_MAX_BACKOFF (%s)",
+				curve.prefix, curve.retry.InitialBackoff, curve.prefix, curve.retry.MaxBackoff))
+		}
+	}
+	if c.Chart.Width < 1 || c.Chart.Height < 1 || c.Chart.BarWidth < 1 {
+		problems = append(problems, fmt.Sprintf("CHART_WIDTH, CHART_HEIGHT and CHART_BAR_WIDTH must be positive, got %dx%d with bar width %d", c.Chart.Width, c.Chart.Height, c.Chart.BarWidth))
+	}
+	if c.ChartURLExpiry != 0 && (c.ChartURLExpiry < MinChartURLExpiry || c.ChartURLExpiry > MaxChartURLExpiry) {
+		problems = append(problems, fmt.Sprintf("CHART_URL_EXPIRY must be between %s and %s, got %s", MinChartURLExpiry, MaxChartURLExpiry, c.ChartURLExpiry))
+	}
+	if !supabaseBucketPattern.MatchString(c.SupabaseBucket) {
+		problems = append(problems, fmt.Sprintf("SUPABASE_BUCKET %q must be lowercase letters, digits, dots, hyphens or underscores", c.SupabaseBucket))
+	}
+	if c.ReportRetentionDays < 0 {
+		problems = append(problems, fmt.Sprintf("REPORT_RETENTION_DAYS must not be negative, got %d", c.ReportRetentionDays))
+	}
+	if c.ReportMaxFiles < 0 {
+		problems = append(problems, fmt.Sprintf("REPORT_MAX_FILES must not be negative, got %d", c.ReportMaxFiles))
+	}
+
+	for name, raw := range map[string]string{
+		"SLACK_WEBHOOK_URL":   c.SlackWebhook,
+		"DISCORD_WEBHOOK_URL": c.DiscordWebhook,
+	} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s must be an http(s) URL", name))
+		}
+	}
+
+	if (c.TelegramBotToken == "") != (c.TelegramChatID == "") {
+		problems = append(problems, "Telegram needs both TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID")
+	}
+
+	if problem := c.emailProblem(); problem != "" {
+		problems = append(problems, problem)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(problems))
+	for i, problem := range problems {
+		errs[i] = errors.New(problem)
+	}
+	return errors.Join(errs...)
+}
+
+// emailProblem reports a partially configured email setup
+func (c *MonitorConfig) emailProblem() string {
+	hasCredentials := c.EmailAuth != "" || c.SMTPOAuth != nil
+	hasRecipients := len(c.EmailTo) > 0 || len(c.EmailGroups) > 0
+
+	if c.EmailUser == "" && !hasCredentials && !hasRecipients {
+		return ""
+	}
+
+	var missing []string
+	if c.EmailUser == "" {
+		missing = append(missing, "EMAIL_USER")
+	}
+	if !hasCredentials {
+		missing = append(missing, "EMAIL_AUTH (or SMTP_AUTH_METHOD=xoauth2)")
+	}
+	if !hasRecipients {
+		missing = append(missing, "EMAIL_TO or EMAIL_GROUPS")
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return "email is partially configured; missing " + strings.Join(missing, ", ")
+}
+
+// validateDomain checks that a MONITOR_DOMAINS entry can be turned into a check
+func validateDomain(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("empty entry")
+	}
+	if strings.ContainsAny(domain, " \t") {
+		return fmt.Errorf("contains whitespace")
+	}
+
+	switch {
+	case strings.HasPrefix(domain, TCPScheme):
+		if _, _, err := net.SplitHostPort(strings.TrimPrefix(domain, TCPScheme)); err != nil {
+			return fmt.Errorf("tcp checks need host:port: %v", err)
+		}
+		return nil
+	case strings.HasPrefix(domain, SRVPrefix):
+		if strings.TrimPrefix(domain, SRVPrefix) == "" {
+			return fmt.Errorf("srv entry has no name")
+		}
+		return nil
+	}
+
+	raw := domain
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("no host")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package uptime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runValidator runs the domain's validator command with the response body on
+// stdin. A first stdout word of up, degraded or down sets the status;
+// otherwise exit code 0 is up, 1 is degraded and anything else is down. The
+// returned message is the validator's output when it did not report up.
+func (m *UptimeMonitor) runValidator(ctx context.Context, command string, result HealthCheckResult, body []byte) (string, string) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"DOMAIN="+result.Domain,
+		"CHECK_URL="+result.URL,
+		fmt.Sprintf("STATUS_CODE=%d", result.StatusCode),
+		fmt.Sprintf("RESPONSE_TIME_MS=%d", result.ResponseTime),
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		output = strings.TrimSpace(stderr.String())
+	}
+
+	status := ""
+	if fields := strings.Fields(stdout.String()); len(fields) > 0 {
+		switch word := strings.ToLower(fields[0]); word {
+		case StatusUp, StatusDegraded, StatusDown:
+			status = word
+		}
+	}
+
+	if status == "" {
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			status = StatusUp
+		case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+			status = StatusDegraded
+		default:
+			status = StatusDown
+			if output == "" {
+				output = err.Error()
+			}
+		}
+	}
+
+	if status == StatusUp {
+		return status, ""
+	}
+	return status, fmt.Sprintf("Validator reported %s: %s", status, output)
+}
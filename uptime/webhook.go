@@ -0,0 +1,102 @@
+package uptime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// WebhookNotifierConfig configures the generic webhook notifier. Template is a
+// text/template executed with the MonitorReport that must render valid JSON.
+type WebhookNotifierConfig struct {
+	URL      string
+	Method   string
+	Headers  map[string]string
+	Template string
+}
+
+// webhookTemplateFuncs are available in WEBHOOK_TEMPLATE; json encodes a value
+// so strings are quoted and escaped correctly
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// parseWebhookNotifierConfig reads the WEBHOOK_* settings, returning nil when
+// WEBHOOK_URL is unset
+func parseWebhookNotifierConfig() (*WebhookNotifierConfig, error) {
+	webhookURL := getEnv("WEBHOOK_URL")
+	if webhookURL == "" {
+		return nil, nil
+	}
+
+	cfg := &WebhookNotifierConfig{
+		URL:      webhookURL,
+		Method:   strings.ToUpper(getEnvOrDefault("WEBHOOK_METHOD", http.MethodPost)),
+		Template: getEnv("WEBHOOK_TEMPLATE"),
+	}
+
+	if cfg.Template == "" {
+		return nil, fmt.Errorf("WEBHOOK_TEMPLATE is required when WEBHOOK_URL is set")
+	}
+	if _, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(cfg.Template); err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_TEMPLATE: %w", err)
+	}
+
+	if raw := getEnv("WEBHOOK_HEADERS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.Headers); err != nil {
+			return nil, fmt.Errorf("invalid WEBHOOK_HEADERS: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// webhookNotifier sends a report rendered through a user-supplied template
+type webhookNotifier struct {
+	monitor *UptimeMonitor
+	config  *WebhookNotifierConfig
+}
+
+func (n *webhookNotifier) Name() string { return "webhook" }
+
+func (n *webhookNotifier) Notify(ctx context.Context, report *MonitorReport, changes []StatusChange) (bool, error) {
+	alerting := len(n.monitor.AlertResults(report, changes)) > 0
+	if !alerting && len(n.monitor.recoveredDomains(report, changes)) == 0 {
+		return false, nil
+	}
+
+	body, err := renderWebhookTemplate(n.config.Template, report)
+	if err != nil {
+		return false, err
+	}
+
+	if err := n.monitor.sendWebhookRequest(ctx, n.config.Method, n.config.URL, body, n.config.Headers); err != nil {
+		return false, err
+	}
+	return alerting, nil
+}
+
+// renderWebhookTemplate executes the template and checks the result is JSON
+func renderWebhookTemplate(text string, report *MonitorReport) ([]byte, error) {
+	tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template rendered invalid JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}